@@ -28,6 +28,9 @@ type HealthStatusResponse struct {
 	// Connectivity status to each other node
 	Nodes []*NodeStatus `json:"nodes"`
 
+	// Rolled-up connectivity counts over all known nodes
+	Summary *HealthStatusSummary `json:"summary,omitempty"`
+
 	// timestamp
 	Timestamp string `json:"timestamp,omitempty"`
 }
@@ -44,6 +47,10 @@ func (m *HealthStatusResponse) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateSummary(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
@@ -95,6 +102,25 @@ func (m *HealthStatusResponse) validateNodes(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *HealthStatusResponse) validateSummary(formats strfmt.Registry) error {
+	if swag.IsZero(m.Summary) { // not required
+		return nil
+	}
+
+	if m.Summary != nil {
+		if err := m.Summary.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("summary")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("summary")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 // ContextValidate validate this health status response based on the context it is used
 func (m *HealthStatusResponse) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
 	var res []error
@@ -107,6 +133,10 @@ func (m *HealthStatusResponse) ContextValidate(ctx context.Context, formats strf
 		res = append(res, err)
 	}
 
+	if err := m.contextValidateSummary(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
@@ -159,6 +189,27 @@ func (m *HealthStatusResponse) contextValidateNodes(ctx context.Context, formats
 	return nil
 }
 
+func (m *HealthStatusResponse) contextValidateSummary(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Summary != nil {
+
+		if swag.IsZero(m.Summary) { // not required
+			return nil
+		}
+
+		if err := m.Summary.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("summary")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("summary")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 // MarshalBinary interface implementation
 func (m *HealthStatusResponse) MarshalBinary() ([]byte, error) {
 	if m == nil {
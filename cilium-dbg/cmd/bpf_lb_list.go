@@ -137,7 +137,17 @@ var bpfLBListCmd = &cobra.Command{
 		// initializing the Go object representing the map. We don't need to
 		// pass the correct sizes here because once the maps are opened, their
 		// size will be read.
-		lbmap.Init(lbmap.InitParams{IPv4: true, IPv6: true})
+		if err := lbmap.Init(lbmap.InitParams{
+			IPv4: true, IPv6: true,
+			ServiceMapMaxEntries:     lbmap.DefaultMaxEntries,
+			BackEndMapMaxEntries:     lbmap.DefaultMaxEntries,
+			RevNatMapMaxEntries:      lbmap.DefaultMaxEntries,
+			AffinityMapMaxEntries:    lbmap.DefaultMaxEntries,
+			SourceRangeMapMaxEntries: lbmap.DefaultMaxEntries,
+			MaglevMapMaxEntries:      lbmap.DefaultMaxEntries,
+		}); err != nil {
+			Fatalf("Unable to initialize lbmap: %s", err)
+		}
 
 		var firstTitle string
 		secondTitle := backendAddressTitle
@@ -328,9 +328,12 @@ func newDaemon(ctx context.Context, cleaner *daemonCleanup, params *daemonParams
 	// Check the kernel if we can make use of managed neighbor entries which
 	// simplifies and fully 'offloads' L2 resolution handling to the kernel.
 	if !option.Config.DryMode {
-		if err := probes.HaveManagedNeighbors(); err == nil {
+		switch err := probes.HaveManagedNeighbors(); {
+		case err == nil:
 			log.Info("Using Managed Neighbor Kernel support")
 			option.Config.ARPPingKernelManaged = true
+		case errors.Is(err, probes.ErrProbeSetup):
+			return nil, nil, fmt.Errorf("failed to probe managed neighbor support: %w", err)
 		}
 	}
 
@@ -378,7 +381,9 @@ func newDaemon(ctx context.Context, cleaner *daemonCleanup, params *daemonParams
 	if option.Config.LBMaglevMapEntries > 0 {
 		lbmapInitParams.MaglevMapMaxEntries = option.Config.LBMaglevMapEntries
 	}
-	lbmap.Init(lbmapInitParams)
+	if err := lbmap.Init(lbmapInitParams); err != nil {
+		return nil, nil, fmt.Errorf("unable to initialize lbmap: %w", err)
+	}
 
 	params.NodeManager.Subscribe(params.Datapath.Node())
 
@@ -191,11 +191,22 @@ func (d *Daemon) launchHubble() {
 			"metrics": option.Config.HubbleMetrics,
 			"tls":     option.Config.HubbleMetricsServerTLSEnabled,
 		}).Info("Starting Hubble Metrics server")
-		if err := metrics.EnableMetrics(log, option.Config.HubbleMetricsServer, metricsTLSConfig, option.Config.HubbleMetrics, grpcMetrics, option.Config.EnableHubbleOpenMetrics); err != nil {
+		if err := metrics.EnableMetrics(log, option.Config.HubbleMetricsServer, metricsTLSConfig, option.Config.HubbleMetrics, grpcMetrics, option.Config.EnableHubbleOpenMetrics, ""); err != nil {
 			logger.WithError(err).Warn("Failed to initialize Hubble metrics server")
 			return
 		}
 
+		go func() {
+			<-d.ctx.Done()
+			// Bound the drain so agent shutdown cannot hang on a
+			// backlogged deletion queue.
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := metrics.ShutdownEndpointDeletionHandler(ctx); err != nil {
+				logger.WithError(err).Warning("Failed to drain Hubble endpoint deletion queue")
+			}
+		}()
+
 		observerOpts = append(observerOpts,
 			observeroption.WithOnDecodedFlowFunc(func(ctx context.Context, flow *flowpb.Flow) (bool, error) {
 				err := metrics.ProcessFlow(ctx, flow)
@@ -22,7 +22,7 @@ import (
 
 func (igc *GC) startKVStoreModeGC(ctx context.Context) error {
 	log.WithField(logfields.Interval, igc.gcInterval).Info("Starting kvstore identity garbage collector")
-	backend, err := kvstoreallocator.NewKVStoreBackend(cache.IdentitiesPath, "", nil, kvstore.Client())
+	backend, err := kvstoreallocator.NewKVStoreBackend(kvstoreallocator.KVStoreBackendConfiguration{BasePath: cache.IdentitiesPath, Backend: kvstore.Client()})
 	if err != nil {
 		return fmt.Errorf("unable to initialize kvstore backend for identity allocation")
 	}
@@ -50,6 +50,7 @@ func (igc *GC) runKVStoreModeGC(ctx context.Context) error {
 
 		keysToDelete, gcStats, err := igc.allocator.RunGC(igc.rateLimiter, keysToDeletePrev)
 		gcDuration := time.Since(now)
+		igc.metrics.IdentityGCDuration.Observe(gcDuration.Seconds())
 		if err != nil {
 			igc.logger.WithError(err).Warning("Unable to run security identity garbage collector")
 
@@ -71,6 +72,7 @@ func (igc *GC) runKVStoreModeGC(ctx context.Context) error {
 
 			igc.metrics.IdentityGCSize.WithLabelValues(LabelValueOutcomeAlive).Set(float64(gcStats.Alive))
 			igc.metrics.IdentityGCSize.WithLabelValues(LabelValueOutcomeDeleted).Set(float64(gcStats.Deleted))
+			igc.metrics.IdentityGCLockFailures.Add(float64(gcStats.LockFailures))
 		}
 
 		if igc.gcInterval <= gcDuration {
@@ -43,6 +43,18 @@ func NewMetrics() *Metrics {
 			Name:      "identity_gc_runs",
 			Help:      "The number of times identity garbage collector has run",
 		}, []string{LabelOutcome}),
+
+		IdentityGCDuration: metric.NewHistogram(metric.HistogramOpts{
+			Namespace: metrics.CiliumOperatorNamespace,
+			Name:      "identity_gc_duration_seconds",
+			Help:      "Duration of an identity garbage collector run",
+		}),
+
+		IdentityGCLockFailures: metric.NewCounter(metric.CounterOpts{
+			Namespace: metrics.CiliumOperatorNamespace,
+			Name:      "identity_gc_lock_failures_total",
+			Help:      "The number of identities skipped by the garbage collector because the kvstore lock could not be acquired",
+		}),
 	}
 }
 
@@ -52,4 +64,11 @@ type Metrics struct {
 
 	// IdentityGCRuns records how many times identity GC has run
 	IdentityGCRuns metric.Vec[metric.Gauge]
+
+	// IdentityGCDuration records the duration of the identity GC runs
+	IdentityGCDuration metric.Histogram
+
+	// IdentityGCLockFailures records the number of identities skipped
+	// because the GC lock could not be acquired
+	IdentityGCLockFailures metric.Counter
 }
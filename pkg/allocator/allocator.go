@@ -192,14 +192,20 @@ type GCStats struct {
 
 	// Deleted is the number of identities deleted
 	Deleted int
+
+	// LockFailures is the number of identities which were skipped during
+	// the run because the GC lock could not be acquired
+	LockFailures int
 }
 
 // Backend represents clients to remote ID allocation systems, such as KV
 // Stores. These are used to coordinate key->ID allocation between cilium
 // nodes.
 type Backend interface {
-	// DeleteAllKeys will delete all keys. It is used in tests.
-	DeleteAllKeys(ctx context.Context)
+	// DeleteAllKeys will delete all keys. It returns the number of keys
+	// deleted if known to the backend. It is used in tests and during
+	// cluster resets.
+	DeleteAllKeys(ctx context.Context) (int, error)
 
 	// Encode encodes a key string as required to conform to the key
 	// restrictions of the backend
@@ -863,9 +869,11 @@ func (a *Allocator) RunLocksGC(ctx context.Context, staleLocksPrevRound map[stri
 	return a.backend.RunLocksGC(ctx, staleLocksPrevRound)
 }
 
-// DeleteAllKeys will delete all keys. It is expected to be used in tests.
-func (a *Allocator) DeleteAllKeys() {
-	a.backend.DeleteAllKeys(context.TODO())
+// DeleteAllKeys will delete all keys. It returns the number of keys deleted
+// if known to the backend. It is expected to be used in tests and during
+// cluster resets.
+func (a *Allocator) DeleteAllKeys() (int, error) {
+	return a.backend.DeleteAllKeys(context.TODO())
 }
 
 // syncLocalKeys checks the kvstore and verifies that a master key exists for
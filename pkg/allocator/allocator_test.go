@@ -45,10 +45,12 @@ func (d *dummyBackend) Encode(v string) string {
 	return v
 }
 
-func (d *dummyBackend) DeleteAllKeys(ctx context.Context) {
+func (d *dummyBackend) DeleteAllKeys(ctx context.Context) (int, error) {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
+	deleted := len(d.identities)
 	d.identities = map[idpool.ID]AllocatorKey{}
+	return deleted, nil
 }
 
 func (d *dummyBackend) AllocateID(ctx context.Context, id idpool.ID, key AllocatorKey) (AllocatorKey, error) {
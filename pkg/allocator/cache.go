@@ -100,6 +100,21 @@ type CacheMutations interface {
 	OnDelete(id idpool.ID, key AllocatorKey)
 }
 
+// CacheMutationsResync is an optional extension of CacheMutations. Backends
+// whose ListAndWatch can restart its list phase, e.g. after a silent watch
+// reconnection, invoke OnResync on handlers implementing it.
+type CacheMutationsResync interface {
+	CacheMutations
+
+	// OnResync is called instead of OnListDone when the backend completed
+	// a new list phase after the initial one, meaning a fresh snapshot has
+	// been replayed. All OnUpsert calls belonging to the replayed snapshot
+	// are delivered before OnResync and from the same goroutine as
+	// OnUpsert/OnDelete, so on invocation the handler sees a
+	// known-complete state it can reconcile against.
+	OnResync()
+}
+
 func (c *cache) sendEvent(typ AllocatorChangeKind, id idpool.ID, key AllocatorKey) {
 	if events := c.allocator.events; events != nil {
 		events <- AllocatorEvent{Typ: typ, ID: id, Key: key}
@@ -93,9 +93,19 @@ func (r *RoutePolicyReconciler) Reconcile(ctx context.Context, params ReconcileP
 			if err != nil {
 				return fmt.Errorf("failed to convert BGP PathAttributes to a RoutePolicy: %w", err)
 			}
-			if len(exportPolicy.Statements) > 0 {
-				desiredPolicies[exportPolicy.Name] = exportPolicy
+			if len(exportPolicy.Statements) == 0 {
+				continue
+			}
+			// Two advertisements with the same selector towards the same peer
+			// produce policies with the same name. Merge them instead of
+			// overwriting, so that communities from both are preserved.
+			if existing, found := desiredPolicies[exportPolicy.Name]; found {
+				exportPolicy, err = mergeRoutePolicies(existing, exportPolicy)
+				if err != nil {
+					return fmt.Errorf("failed to merge route policies: %w", err)
+				}
 			}
+			desiredPolicies[exportPolicy.Name] = exportPolicy
 		}
 	}
 
@@ -361,6 +371,69 @@ func policyStatement(neighborAddr string, prefixes []*types.RoutePolicyPrefixMat
 	}
 }
 
+// mergeRoutePolicies merges two route policies with the same name and type into
+// a single policy. Statements with identical match conditions are folded into
+// one, with their community sets unioned and the higher local preference
+// retained; statements with distinct conditions are simply concatenated.
+func mergeRoutePolicies(policyA, policyB *types.RoutePolicy) (*types.RoutePolicy, error) {
+	if policyA == nil || policyB == nil {
+		return nil, fmt.Errorf("route policy is nil")
+	}
+	if policyA.Name != policyB.Name {
+		return nil, fmt.Errorf("route policy names do not match: %s vs %s", policyA.Name, policyB.Name)
+	}
+	if policyA.Type != policyB.Type {
+		return nil, fmt.Errorf("route policy types of %s do not match", policyA.Name)
+	}
+
+	merged := &types.RoutePolicy{
+		Name: policyA.Name,
+		Type: policyA.Type,
+	}
+
+	for _, s := range append(policyA.Statements, policyB.Statements...) {
+		var existing *types.RoutePolicyStatement
+		for _, m := range merged.Statements {
+			if m.Conditions.DeepEqual(&s.Conditions) {
+				existing = m
+				break
+			}
+		}
+		if existing == nil {
+			merged.Statements = append(merged.Statements, &types.RoutePolicyStatement{
+				Conditions: s.Conditions,
+				Actions: types.RoutePolicyActions{
+					RouteAction:         s.Actions.RouteAction,
+					AddCommunities:      append([]string(nil), s.Actions.AddCommunities...),
+					AddLargeCommunities: append([]string(nil), s.Actions.AddLargeCommunities...),
+					SetLocalPreference:  s.Actions.SetLocalPreference,
+				},
+			})
+			continue
+		}
+		if existing.Actions.RouteAction != s.Actions.RouteAction {
+			return nil, fmt.Errorf("conflicting route actions in policy %s", policyA.Name)
+		}
+		existing.Actions.AddCommunities = dedupStrings(append(existing.Actions.AddCommunities, s.Actions.AddCommunities...))
+		existing.Actions.AddLargeCommunities = dedupStrings(append(existing.Actions.AddLargeCommunities, s.Actions.AddLargeCommunities...))
+		if s.Actions.SetLocalPreference != nil &&
+			(existing.Actions.SetLocalPreference == nil || *s.Actions.SetLocalPreference > *existing.Actions.SetLocalPreference) {
+			existing.Actions.SetLocalPreference = s.Actions.SetLocalPreference
+		}
+	}
+
+	return merged, nil
+}
+
+// dedupStrings returns the provided strings deduplicated and sorted, to keep
+// the merged policies stable for DeepEqual comparisons.
+func dedupStrings(in []string) []string {
+	if len(in) == 0 {
+		return in
+	}
+	return sets.List(sets.New(in...))
+}
+
 // peerAddressFromPolicy returns the first neighbor address found in a routing policy.
 func peerAddressFromPolicy(p *types.RoutePolicy) string {
 	if p == nil {
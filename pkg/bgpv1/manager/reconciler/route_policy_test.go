@@ -753,3 +753,84 @@ func TestCommunityDeduplication(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeRoutePolicies(t *testing.T) {
+	prefix := netip.MustParsePrefix("10.0.0.0/24")
+	statement := func(communities []string, localPref *int64) *types.RoutePolicyStatement {
+		return &types.RoutePolicyStatement{
+			Conditions: types.RoutePolicyConditions{
+				MatchNeighbors: []string{"172.16.0.1/32"},
+				MatchPrefixes: []*types.RoutePolicyPrefixMatch{
+					{CIDR: prefix, PrefixLenMin: prefix.Bits(), PrefixLenMax: prefix.Bits()},
+				},
+			},
+			Actions: types.RoutePolicyActions{
+				RouteAction:        types.RoutePolicyActionNone,
+				AddCommunities:     communities,
+				SetLocalPreference: localPref,
+			},
+		}
+	}
+
+	var table = []struct {
+		name      string
+		policyA   *types.RoutePolicy
+		policyB   *types.RoutePolicy
+		expected  *types.RoutePolicy
+		expectErr bool
+	}{
+		{
+			name:      "nil policy",
+			policyA:   &types.RoutePolicy{Name: "policy"},
+			policyB:   nil,
+			expectErr: true,
+		},
+		{
+			name:      "name mismatch",
+			policyA:   &types.RoutePolicy{Name: "policy-a"},
+			policyB:   &types.RoutePolicy{Name: "policy-b"},
+			expectErr: true,
+		},
+		{
+			name: "union communities on matching conditions",
+			policyA: &types.RoutePolicy{
+				Name:       "policy",
+				Statements: []*types.RoutePolicyStatement{statement([]string{"64125:100"}, nil)},
+			},
+			policyB: &types.RoutePolicy{
+				Name:       "policy",
+				Statements: []*types.RoutePolicyStatement{statement([]string{"64125:200", "64125:100"}, nil)},
+			},
+			expected: &types.RoutePolicy{
+				Name:       "policy",
+				Statements: []*types.RoutePolicyStatement{statement([]string{"64125:100", "64125:200"}, nil)},
+			},
+		},
+		{
+			name: "higher local preference wins",
+			policyA: &types.RoutePolicy{
+				Name:       "policy",
+				Statements: []*types.RoutePolicyStatement{statement(nil, ptr.To[int64](100))},
+			},
+			policyB: &types.RoutePolicy{
+				Name:       "policy",
+				Statements: []*types.RoutePolicyStatement{statement(nil, ptr.To[int64](200))},
+			},
+			expected: &types.RoutePolicy{
+				Name:       "policy",
+				Statements: []*types.RoutePolicyStatement{statement(nil, ptr.To[int64](200))},
+			},
+		},
+	}
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := mergeRoutePolicies(tt.policyA, tt.policyB)
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.EqualValues(t, tt.expected, merged)
+		})
+	}
+}
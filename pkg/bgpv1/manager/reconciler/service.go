@@ -10,9 +10,12 @@ import (
 	"slices"
 
 	"github.com/cilium/hive/cell"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/exp/maps"
 	corev1 "k8s.io/api/core/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/cilium/cilium/pkg/bgpv1/manager/instance"
 	"github.com/cilium/cilium/pkg/bgpv1/manager/store"
@@ -24,6 +27,7 @@ import (
 	"github.com/cilium/cilium/pkg/k8s/slim/k8s/apis/labels"
 	slim_metav1 "github.com/cilium/cilium/pkg/k8s/slim/k8s/apis/meta/v1"
 	ciliumslices "github.com/cilium/cilium/pkg/slices"
+	"github.com/cilium/cilium/pkg/time"
 )
 
 type LBServiceReconcilerOut struct {
@@ -35,8 +39,64 @@ type LBServiceReconcilerOut struct {
 type ServiceReconciler struct {
 	diffStore   store.DiffStore[*slim_corev1.Service]
 	epDiffStore store.DiffStore[*k8s.Endpoints]
+
+	// acceptedLoadBalancerClasses is the set of LoadBalancerClass values for
+	// which LoadBalancer services are advertised, for environments running
+	// multiple LB implementations. Empty means the default behavior: only
+	// services with no class or the BGP control plane class are advertised.
+	// The wildcard entry "*" accepts any class.
+	acceptedLoadBalancerClasses []string
+
+	// eventRecorder, if set, is used to post an event on a Service when its
+	// routes are withdrawn because no local endpoints remain. Nil disables
+	// event emission.
+	eventRecorder record.EventRecorder
+
+	// lastNoEndpointsEvent tracks when the no-local-endpoints event was last
+	// posted per service, to rate-limit events while endpoints flap.
+	lastNoEndpointsEvent map[resource.Key]time.Time
+
+	// aggregationLengthIPv4 and aggregationLengthIPv6 hold the prefix lengths
+	// into which per-service host routes are aggregated before advertisement.
+	// A negative value disables aggregation for that family.
+	aggregationLengthIPv4 int
+	aggregationLengthIPv6 int
+
+	// clusterIPReadinessGate, when enabled, withdraws ClusterIP routes of
+	// services without a single ready (non-terminating) backend anywhere
+	// in the cluster, instead of advertising the ClusterIP for as long as
+	// the service object exists.
+	clusterIPReadinessGate bool
+
+	// readyServices is the set of services with at least one ready backend
+	// cluster-wide, rebuilt from the endpoints diffstore on every Reconcile
+	// run when clusterIPReadinessGate is enabled.
+	readyServices map[k8s.ServiceID]struct{}
+
+	// svcLabelCache memoizes the label set built by serviceLabelSet per
+	// service, so a service reconciled against many advertisements does
+	// not re-clone its labels each time. Entries are invalidated when the
+	// service's UID or resource version changes and dropped on withdrawal.
+	svcLabelCache map[resource.Key]cachedLabelSet
+}
+
+// cachedLabelSet is a svcLabelCache entry, valid as long as the service still
+// has the recorded UID and resource version.
+type cachedLabelSet struct {
+	uid             k8stypes.UID
+	resourceVersion string
+	labels          labels.Labels
 }
 
+// noLocalEndpointsEventInterval is the minimum time between two
+// no-local-endpoints events for the same service.
+const noLocalEndpointsEventInterval = time.Minute
+
+// serviceNextHopAnnotation, when set on a Service, overrides the next hop of
+// the routes advertised for it. The value must be a plain IP address; it is
+// applied only to advertised prefixes of the same address family.
+const serviceNextHopAnnotation = "cilium.io/bgp-service-next-hop"
+
 // LBServiceReconcilerMetadata keeps a map of services to the respective advertised Paths
 type LBServiceReconcilerMetadata map[resource.Key][]*types.Path
 
@@ -49,12 +109,48 @@ func NewServiceReconciler(diffStore store.DiffStore[*slim_corev1.Service], epDif
 
 	return LBServiceReconcilerOut{
 		Reconciler: &ServiceReconciler{
-			diffStore:   diffStore,
-			epDiffStore: epDiffStore,
+			diffStore:             diffStore,
+			epDiffStore:           epDiffStore,
+			aggregationLengthIPv4: -1,
+			aggregationLengthIPv6: -1,
 		},
 	}
 }
 
+// SetRouteAggregation enables aggregation of per-service host routes into the
+// given prefix lengths, one summarized path per covered range instead of one
+// path per service IP. A negative length disables aggregation for that
+// family. Lengths exceeding the host length of the family (32 for IPv4, 128
+// for IPv6) are rejected, so a misconfigured length surfaces as an error
+// instead of prefixes silently not being aggregated. Must be called before
+// the reconciler is run.
+func (r *ServiceReconciler) SetRouteAggregation(ipv4Length, ipv6Length int) error {
+	if ipv4Length > 32 {
+		return fmt.Errorf("IPv4 aggregation length %d exceeds the host length of 32", ipv4Length)
+	}
+	if ipv6Length > 128 {
+		return fmt.Errorf("IPv6 aggregation length %d exceeds the host length of 128", ipv6Length)
+	}
+	r.aggregationLengthIPv4 = ipv4Length
+	r.aggregationLengthIPv6 = ipv6Length
+	return nil
+}
+
+// SetClusterIPReadinessGate gates ClusterIP advertisement on the service
+// having at least one ready (non-terminating) backend anywhere in the
+// cluster, so routes of fully down services are withdrawn. Must be called
+// before the reconciler is run.
+func (r *ServiceReconciler) SetClusterIPReadinessGate(enabled bool) {
+	r.clusterIPReadinessGate = enabled
+}
+
+// SetEventRecorder enables posting Kubernetes events on Services whose routes
+// are withdrawn because local endpoints went away. Must be called before the
+// reconciler is run.
+func (r *ServiceReconciler) SetEventRecorder(recorder record.EventRecorder) {
+	r.eventRecorder = recorder
+}
+
 func (r *ServiceReconciler) Name() string {
 	return "Service"
 }
@@ -73,12 +169,52 @@ func (r *ServiceReconciler) Reconcile(ctx context.Context, p ReconcileParams) er
 		return err
 	}
 
-	if r.requiresFullReconciliation(p) {
-		return r.fullReconciliation(ctx, p.CurrentServer, p.DesiredConfig, ls)
+	if r.clusterIPReadinessGate {
+		r.readyServices, err = r.populateReadyServices()
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.requiresFullReconciliation(p) || r.fullReconcileForced(p.CurrentServer) {
+		if err := r.fullReconciliation(ctx, p.CurrentServer, p.DesiredConfig, ls); err != nil {
+			return err
+		}
+		r.clearForceFullReconcile(p.CurrentServer)
+		return nil
 	}
 	return r.svcDiffReconciliation(ctx, p.CurrentServer, p.DesiredConfig, ls)
 }
 
+// serviceForceFullReconcileKey is the ReconcilerMetadata key under which a
+// pending forced full reconciliation is recorded for an instance.
+const serviceForceFullReconcileKey = "Service-ForceFullReconcile"
+
+// ForceFullReconcile marks the given instance so that its next Reconcile run
+// performs a full service reconciliation, regardless of the outcome of the
+// advertisement diff. The mark is cleared once a full pass succeeds.
+func (r *ServiceReconciler) ForceFullReconcile(sc *instance.ServerWithConfig) {
+	if sc == nil {
+		return
+	}
+	sc.ReconcilerMetadata[serviceForceFullReconcileKey] = true
+}
+
+func (r *ServiceReconciler) fullReconcileForced(sc *instance.ServerWithConfig) bool {
+	if sc == nil {
+		return false
+	}
+	forced, _ := sc.ReconcilerMetadata[serviceForceFullReconcileKey].(bool)
+	return forced
+}
+
+func (r *ServiceReconciler) clearForceFullReconcile(sc *instance.ServerWithConfig) {
+	if sc == nil {
+		return
+	}
+	delete(sc.ReconcilerMetadata, serviceForceFullReconcileKey)
+}
+
 func (r *ServiceReconciler) getMetadata(sc *instance.ServerWithConfig) LBServiceReconcilerMetadata {
 	if _, found := sc.ReconcilerMetadata[r.Name()]; !found {
 		sc.ReconcilerMetadata[r.Name()] = make(LBServiceReconcilerMetadata)
@@ -146,6 +282,39 @@ endpointsLoop:
 	return ls, nil
 }
 
+// populateReadyServices returns the set of services backed by at least one
+// ready (non-terminating) endpoint on any node, used by the ClusterIP
+// readiness gate.
+func (r *ServiceReconciler) populateReadyServices() (map[k8s.ServiceID]struct{}, error) {
+	ready := make(map[k8s.ServiceID]struct{})
+
+	epList, err := r.epDiffStore.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpoints from diffstore: %w", err)
+	}
+
+	for _, eps := range epList {
+		if _, found := ready[eps.ServiceID]; found {
+			continue
+		}
+		for _, be := range eps.Backends {
+			if !be.Terminating {
+				ready[eps.ServiceID] = struct{}{}
+				break
+			}
+		}
+	}
+
+	return ready, nil
+}
+
+// hasReadyEndpoints reports whether the service had a ready backend anywhere
+// in the cluster when readyServices was last populated.
+func (r *ServiceReconciler) hasReadyEndpoints(svc *slim_corev1.Service) bool {
+	_, found := r.readyServices[k8s.ServiceID{Name: svc.GetName(), Namespace: svc.GetNamespace()}]
+	return found
+}
+
 func hasLocalEndpoints(svc *slim_corev1.Service, ls localServices) bool {
 	_, found := ls[k8s.ServiceID{Name: svc.GetName(), Namespace: svc.GetNamespace()}]
 	return found
@@ -271,6 +440,39 @@ func (r *ServiceReconciler) diffReconciliationServiceList() (toReconcile []*slim
 	return deduped, deleted, nil
 }
 
+// serviceNextHop returns the next hop override configured on the given
+// service via the serviceNextHopAnnotation, or the zero netip.Addr if the
+// annotation is absent.
+func serviceNextHop(svc *slim_corev1.Service) (netip.Addr, error) {
+	anno, ok := svc.Annotations[serviceNextHopAnnotation]
+	if !ok {
+		return netip.Addr{}, nil
+	}
+	return netip.ParseAddr(anno)
+}
+
+// SetAcceptedLoadBalancerClasses configures the set of LoadBalancerClass
+// values accepted in addition to the default BGP control plane class, see
+// ServiceReconciler.acceptedLoadBalancerClasses.
+func (r *ServiceReconciler) SetAcceptedLoadBalancerClasses(classes []string) {
+	r.acceptedLoadBalancerClasses = classes
+}
+
+// matchesLoadBalancerClass reports whether the LoadBalancerClass of the given
+// service allows advertising it. Services without a class are always
+// advertised.
+func (r *ServiceReconciler) matchesLoadBalancerClass(svc *slim_corev1.Service) bool {
+	if svc.Spec.LoadBalancerClass == nil || *svc.Spec.LoadBalancerClass == v2alpha1api.BGPLoadBalancerClass {
+		return true
+	}
+	for _, class := range r.acceptedLoadBalancerClasses {
+		if class == "*" || class == *svc.Spec.LoadBalancerClass {
+			return true
+		}
+	}
+	return false
+}
+
 // svcDesiredRoutes determines which, if any routes should be announced for the given service. This determines the
 // desired state.
 func (r *ServiceReconciler) svcDesiredRoutes(newc *v2alpha1api.CiliumBGPVirtualRouter, svc *slim_corev1.Service, ls localServices) ([]netip.Prefix, error) {
@@ -286,7 +488,18 @@ func (r *ServiceReconciler) svcDesiredRoutes(newc *v2alpha1api.CiliumBGPVirtualR
 	}
 
 	// Ignore non matching services.
-	if !svcSelector.Matches(serviceLabelSet(svc)) {
+	if !svcSelector.Matches(r.labelSetFor(svc)) {
+		return nil, nil
+	}
+
+	// ExternalName services resolve to a CNAME and carry no routable IPs,
+	// there is nothing to advertise for them. Headless services are skipped
+	// further down by the per-address-type handlers.
+	if svc.Spec.Type == slim_corev1.ServiceTypeExternalName {
+		log.WithFields(logrus.Fields{
+			"service":   svc.Name,
+			"namespace": svc.Namespace,
+		}).Debug("Skipping ExternalName service, no routable IPs to advertise")
 		return nil, nil
 	}
 
@@ -303,7 +516,37 @@ func (r *ServiceReconciler) svcDesiredRoutes(newc *v2alpha1api.CiliumBGPVirtualR
 		}
 	}
 
-	return desiredRoutes, err
+	return r.aggregateRoutes(desiredRoutes), err
+}
+
+// aggregateRoutes maps host routes onto the covering aggregate prefix of the
+// configured length and deduplicates the result, so that adjacent service IPs
+// collapse into a single summarized path. Routes which are not host routes,
+// or whose family has aggregation disabled, pass through unchanged.
+func (r *ServiceReconciler) aggregateRoutes(routes []netip.Prefix) []netip.Prefix {
+	if r.aggregationLengthIPv4 < 0 && r.aggregationLengthIPv6 < 0 {
+		return routes
+	}
+
+	var aggregated []netip.Prefix
+	seen := make(map[netip.Prefix]struct{}, len(routes))
+	for _, route := range routes {
+		length := r.aggregationLengthIPv4
+		if route.Addr().Is6() {
+			length = r.aggregationLengthIPv6
+		}
+		if length >= 0 && length < route.Bits() && route.IsSingleIP() {
+			if agg, err := route.Addr().Prefix(length); err == nil {
+				route = agg
+			}
+		}
+		if _, dup := seen[route]; dup {
+			continue
+		}
+		seen[route] = struct{}{}
+		aggregated = append(aggregated, route)
+	}
+	return aggregated
 }
 
 func (r *ServiceReconciler) externalIPDesiredRoutes(svc *slim_corev1.Service, ls localServices) []netip.Prefix {
@@ -333,6 +576,11 @@ func (r *ServiceReconciler) clusterIPDesiredRoutes(svc *slim_corev1.Service, ls
 		!hasLocalEndpoints(svc, ls) {
 		return desiredRoutes
 	}
+	// With the readiness gate enabled, withdraw the ClusterIP routes of
+	// services without a single ready backend cluster-wide.
+	if r.clusterIPReadinessGate && !r.hasReadyEndpoints(svc) {
+		return desiredRoutes
+	}
 	if svc.Spec.ClusterIP == "" || len(svc.Spec.ClusterIPs) == 0 || svc.Spec.ClusterIP == corev1.ClusterIPNone {
 		return desiredRoutes
 	}
@@ -367,7 +615,7 @@ func (r *ServiceReconciler) lbSvcDesiredRoutes(svc *slim_corev1.Service, ls loca
 		return desiredRoutes
 	}
 	// Ignore service managed by an unsupported LB class.
-	if svc.Spec.LoadBalancerClass != nil && *svc.Spec.LoadBalancerClass != v2alpha1api.BGPLoadBalancerClass {
+	if !r.matchesLoadBalancerClass(svc) {
 		// The service is managed by a different LB class.
 		return desiredRoutes
 	}
@@ -391,15 +639,73 @@ func (r *ServiceReconciler) reconcileService(ctx context.Context, sc *instance.S
 	if err != nil {
 		return fmt.Errorf("failed to retrieve svc desired routes: %w", err)
 	}
+	r.maybeEmitNoLocalEndpointsEvent(sc, svc, ls, desiredRoutes)
 	return r.reconcileServiceRoutes(ctx, sc, svc, desiredRoutes)
 }
 
+// maybeEmitNoLocalEndpointsEvent posts a warning event on svc when one of its
+// previously advertised routes is about to be withdrawn specifically because
+// the service runs with a Local traffic policy and its last local endpoint
+// disappeared. Withdrawals caused by advertisement configuration changes do
+// not emit an event. Events are rate-limited per service to avoid storms when
+// endpoints flap.
+func (r *ServiceReconciler) maybeEmitNoLocalEndpointsEvent(sc *instance.ServerWithConfig, svc *slim_corev1.Service, ls localServices, desiredRoutes []netip.Prefix) {
+	if r.eventRecorder == nil {
+		return
+	}
+	if svc.Spec.ExternalTrafficPolicy != slim_corev1.ServiceExternalTrafficPolicyLocal ||
+		hasLocalEndpoints(svc, ls) {
+		return
+	}
+
+	// Only report routes which were advertised before and are no longer
+	// desired, i.e. are going to be withdrawn by this reconciliation.
+	svcKey := resource.NewKey(svc)
+	withdrawing := false
+	for _, announcement := range r.getMetadata(sc)[svcKey] {
+		if slices.IndexFunc(desiredRoutes, func(existing netip.Prefix) bool {
+			return existing.String() == announcement.NLRI.String()
+		}) == -1 {
+			withdrawing = true
+			break
+		}
+	}
+	if !withdrawing {
+		return
+	}
+
+	if last, ok := r.lastNoEndpointsEvent[svcKey]; ok && time.Since(last) < noLocalEndpointsEventInterval {
+		return
+	}
+	if r.lastNoEndpointsEvent == nil {
+		r.lastNoEndpointsEvent = make(map[resource.Key]time.Time)
+	}
+	r.lastNoEndpointsEvent[svcKey] = time.Now()
+
+	r.eventRecorder.Event(&slim_corev1.Service{
+		TypeMeta: slim_metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: slim_metav1.ObjectMeta{
+			Name:      svc.Name,
+			Namespace: svc.Namespace,
+		},
+	}, corev1.EventTypeWarning, "WithdrawingBGPRoutes",
+		"Withdrawing BGP routes: externalTrafficPolicy is Local and no local endpoints remain")
+}
+
 // reconcileServiceRoutes ensures that desired routes of a given service are announced,
 // adding missing announcements or withdrawing unwanted ones.
 func (r *ServiceReconciler) reconcileServiceRoutes(ctx context.Context, sc *instance.ServerWithConfig, svc *slim_corev1.Service, desiredRoutes []netip.Prefix) error {
 	serviceAnnouncements := r.getMetadata(sc)
 	svcKey := resource.NewKey(svc)
 
+	nextHop, err := serviceNextHop(svc)
+	if err != nil {
+		return fmt.Errorf("failed to parse next hop of service %s/%s: %w", svc.Namespace, svc.Name, err)
+	}
+
 	for _, desiredCidr := range desiredRoutes {
 		// If this route has already been announced, don't add it again
 		if slices.IndexFunc(serviceAnnouncements[svcKey], func(existing *types.Path) bool {
@@ -408,9 +714,25 @@ func (r *ServiceReconciler) reconcileServiceRoutes(ctx context.Context, sc *inst
 			continue
 		}
 
-		// Advertise the new cidr
+		// With aggregation enabled, another service may already announce the
+		// same aggregate prefix. Record a reference to its path instead of
+		// re-advertising.
+		if existing := announcedPathForNLRI(serviceAnnouncements, svcKey, desiredCidr.String()); existing != nil {
+			serviceAnnouncements[svcKey] = append(serviceAnnouncements[svcKey], existing)
+			continue
+		}
+
+		// Advertise the new cidr, overriding the next hop if one of the
+		// matching address family was configured on the service.
+		path := types.NewPathForPrefix(desiredCidr)
+		if nextHop.IsValid() && nextHop.Is4() == desiredCidr.Addr().Is4() {
+			path, err = types.NewPathForPrefixWithNextHop(desiredCidr, nextHop)
+			if err != nil {
+				return fmt.Errorf("failed to build path for service route %v: %w", desiredCidr, err)
+			}
+		}
 		advertPathResp, err := sc.Server.AdvertisePath(ctx, types.PathRequest{
-			Path: types.NewPathForPrefix(desiredCidr),
+			Path: path,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to advertise service route %v: %w", desiredCidr, err)
@@ -428,8 +750,12 @@ func (r *ServiceReconciler) reconcileServiceRoutes(ctx context.Context, sc *inst
 			continue
 		}
 
-		if err := sc.Server.WithdrawPath(ctx, types.PathRequest{Path: announcement}); err != nil {
-			return fmt.Errorf("failed to withdraw service route %s: %w", announcement.NLRI, err)
+		// Only withdraw the path once the last service referencing it is
+		// gone; an aggregate prefix can be shared across services.
+		if announcedPathForNLRI(serviceAnnouncements, svcKey, announcement.NLRI.String()) == nil {
+			if err := sc.Server.WithdrawPath(ctx, types.PathRequest{Path: announcement}); err != nil {
+				return fmt.Errorf("failed to withdraw service route %s: %w", announcement.NLRI, err)
+			}
 		}
 
 		// Delete announcement from slice
@@ -438,13 +764,36 @@ func (r *ServiceReconciler) reconcileServiceRoutes(ctx context.Context, sc *inst
 	return nil
 }
 
+// announcedPathForNLRI returns a path with the given NLRI announced for any
+// service other than skip, or nil if no other service references it.
+func announcedPathForNLRI(serviceAnnouncements LBServiceReconcilerMetadata, skip resource.Key, nlri string) *types.Path {
+	for key, paths := range serviceAnnouncements {
+		if key == skip {
+			continue
+		}
+		for _, path := range paths {
+			if path.NLRI.String() == nlri {
+				return path
+			}
+		}
+	}
+	return nil
+}
+
 // withdrawService removes all announcements for the given service
 func (r *ServiceReconciler) withdrawService(ctx context.Context, sc *instance.ServerWithConfig, key resource.Key) error {
+	delete(r.svcLabelCache, key)
 	serviceAnnouncements := r.getMetadata(sc)
 	advertisements := serviceAnnouncements[key]
 	// Loop in reverse order so we can delete without effect to the iteration.
 	for i := len(advertisements) - 1; i >= 0; i-- {
 		advertisement := advertisements[i]
+		// Skip the withdraw if another service still references the same
+		// (possibly aggregated) prefix, see announcedPathForNLRI.
+		if announcedPathForNLRI(serviceAnnouncements, key, advertisement.NLRI.String()) != nil {
+			advertisements = slices.Delete(advertisements, i, i+1)
+			continue
+		}
 		if err := sc.Server.WithdrawPath(ctx, types.PathRequest{Path: advertisement}); err != nil {
 			// Persist remaining advertisements
 			serviceAnnouncements[key] = advertisements
@@ -461,6 +810,27 @@ func (r *ServiceReconciler) withdrawService(ctx context.Context, sc *instance.Se
 	return nil
 }
 
+// labelSetFor returns the label set of the given service, serving repeated
+// calls for an unchanged service from the cache.
+func (r *ServiceReconciler) labelSetFor(svc *slim_corev1.Service) labels.Labels {
+	key := resource.NewKey(svc)
+	if entry, ok := r.svcLabelCache[key]; ok &&
+		entry.uid == svc.UID && entry.resourceVersion == svc.ResourceVersion {
+		return entry.labels
+	}
+
+	ls := serviceLabelSet(svc)
+	if r.svcLabelCache == nil {
+		r.svcLabelCache = make(map[resource.Key]cachedLabelSet)
+	}
+	r.svcLabelCache[key] = cachedLabelSet{
+		uid:             svc.UID,
+		resourceVersion: svc.ResourceVersion,
+		labels:          ls,
+	}
+	return ls
+}
+
 func serviceLabelSet(svc *slim_corev1.Service) labels.Labels {
 	svcLabels := maps.Clone(svc.Labels)
 	if svcLabels == nil {
@@ -5,11 +5,13 @@ package reconciler
 
 import (
 	"context"
+	"fmt"
 	"net/netip"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 
 	"github.com/cilium/cilium/pkg/bgpv1/manager/instance"
@@ -2390,3 +2392,323 @@ func containsLbClass(svcs []*slim_corev1.Service) bool {
 	}
 	return false
 }
+
+func TestForceFullReconcile(t *testing.T) {
+	r := &ServiceReconciler{}
+	sc := &instance.ServerWithConfig{
+		ReconcilerMetadata: make(map[string]any),
+	}
+
+	require.False(t, r.fullReconcileForced(sc))
+
+	r.ForceFullReconcile(sc)
+	require.True(t, r.fullReconcileForced(sc))
+
+	r.clearForceFullReconcile(sc)
+	require.False(t, r.fullReconcileForced(sc))
+
+	// nil instance must not panic
+	r.ForceFullReconcile(nil)
+	require.False(t, r.fullReconcileForced(nil))
+}
+
+func TestExternalNameServiceNotAdvertised(t *testing.T) {
+	blueSelector := slim_metav1.LabelSelector{MatchLabels: map[string]string{"color": "blue"}}
+
+	svc := &slim_corev1.Service{
+		ObjectMeta: slim_metav1.ObjectMeta{
+			Name:      "svc-external-name",
+			Namespace: "default",
+			Labels:    blueSelector.MatchLabels,
+		},
+		Spec: slim_corev1.ServiceSpec{
+			Type: slim_corev1.ServiceTypeExternalName,
+			// Even if stray addresses are present on the object, an
+			// ExternalName service must not be advertised.
+			ExternalIPs: []string{"192.168.100.1"},
+		},
+	}
+
+	newc := &v2alpha1api.CiliumBGPVirtualRouter{
+		LocalASN:        64125,
+		ServiceSelector: &blueSelector,
+		ServiceAdvertisements: []v2alpha1api.BGPServiceAddressType{
+			v2alpha1api.BGPLoadBalancerIPAddr,
+			v2alpha1api.BGPClusterIPAddr,
+			v2alpha1api.BGPExternalIPAddr,
+		},
+	}
+
+	r := &ServiceReconciler{}
+	routes, err := r.svcDesiredRoutes(newc, svc, make(localServices))
+	require.NoError(t, err)
+	require.Empty(t, routes)
+}
+
+func TestMatchesLoadBalancerClass(t *testing.T) {
+	customClass := "acme.io/custom-lb"
+	bgpClass := v2alpha1api.BGPLoadBalancerClass
+
+	svcWithClass := func(class *string) *slim_corev1.Service {
+		return &slim_corev1.Service{
+			Spec: slim_corev1.ServiceSpec{
+				Type:              slim_corev1.ServiceTypeLoadBalancer,
+				LoadBalancerClass: class,
+			},
+		}
+	}
+
+	// Default behavior: only services without a class or with the BGP
+	// control plane class are accepted.
+	r := &ServiceReconciler{}
+	require.True(t, r.matchesLoadBalancerClass(svcWithClass(nil)))
+	require.True(t, r.matchesLoadBalancerClass(svcWithClass(&bgpClass)))
+	require.False(t, r.matchesLoadBalancerClass(svcWithClass(&customClass)))
+
+	// A custom class in the accepted set is advertised, in addition to the
+	// default BGP control plane class.
+	r.SetAcceptedLoadBalancerClasses([]string{customClass})
+	require.True(t, r.matchesLoadBalancerClass(svcWithClass(&customClass)))
+	require.True(t, r.matchesLoadBalancerClass(svcWithClass(&bgpClass)))
+	require.True(t, r.matchesLoadBalancerClass(svcWithClass(nil)))
+	otherClass := "acme.io/other-lb"
+	require.False(t, r.matchesLoadBalancerClass(svcWithClass(&otherClass)))
+
+	// The wildcard accepts any class.
+	r.SetAcceptedLoadBalancerClasses([]string{"*"})
+	require.True(t, r.matchesLoadBalancerClass(svcWithClass(&customClass)))
+	require.True(t, r.matchesLoadBalancerClass(svcWithClass(&bgpClass)))
+}
+
+func TestMaybeEmitNoLocalEndpointsEvent(t *testing.T) {
+	svc := &slim_corev1.Service{
+		ObjectMeta: slim_metav1.ObjectMeta{
+			Name:      "svc-1",
+			Namespace: "default",
+		},
+		Spec: slim_corev1.ServiceSpec{
+			Type:                  slim_corev1.ServiceTypeLoadBalancer,
+			ExternalTrafficPolicy: slim_corev1.ServiceExternalTrafficPolicyLocal,
+		},
+	}
+	sc := &instance.ServerWithConfig{
+		ReconcilerMetadata: make(map[string]any),
+	}
+
+	r := &ServiceReconciler{}
+	path := types.NewPathForPrefix(netip.MustParsePrefix("1.2.3.4/32"))
+	r.getMetadata(sc)[resource.NewKey(svc)] = []*types.Path{path}
+
+	// No recorder configured, must not panic.
+	r.maybeEmitNoLocalEndpointsEvent(sc, svc, localServices{}, nil)
+
+	recorder := record.NewFakeRecorder(10)
+	r.SetEventRecorder(recorder)
+
+	// A previously advertised route withdrawn with no local endpoints left
+	// emits an event.
+	r.maybeEmitNoLocalEndpointsEvent(sc, svc, localServices{}, nil)
+	require.Len(t, recorder.Events, 1)
+	require.Contains(t, <-recorder.Events, "no local endpoints")
+
+	// A second withdrawal within the rate-limit interval does not.
+	r.maybeEmitNoLocalEndpointsEvent(sc, svc, localServices{}, nil)
+	require.Empty(t, recorder.Events)
+
+	// No event when the route is still desired.
+	delete(r.lastNoEndpointsEvent, resource.NewKey(svc))
+	r.maybeEmitNoLocalEndpointsEvent(sc, svc, localServices{}, []netip.Prefix{netip.MustParsePrefix("1.2.3.4/32")})
+	require.Empty(t, recorder.Events)
+
+	// No event when local endpoints exist: the withdrawal is caused by
+	// something else, e.g. an advertisement configuration change.
+	ls := localServices{k8s.ServiceID{Name: svc.Name, Namespace: svc.Namespace}: struct{}{}}
+	r.maybeEmitNoLocalEndpointsEvent(sc, svc, ls, nil)
+	require.Empty(t, recorder.Events)
+}
+
+func TestAggregateRoutes(t *testing.T) {
+	routes := []netip.Prefix{
+		netip.MustParsePrefix("192.168.0.1/32"),
+		netip.MustParsePrefix("192.168.0.2/32"),
+		netip.MustParsePrefix("192.168.1.1/32"),
+		netip.MustParsePrefix("10.0.0.0/16"),
+		netip.MustParsePrefix("fd00:192:168::1/128"),
+		netip.MustParsePrefix("fd00:192:168::2/128"),
+	}
+
+	// Aggregation disabled by default, routes pass through unchanged.
+	r := &ServiceReconciler{aggregationLengthIPv4: -1, aggregationLengthIPv6: -1}
+	require.Equal(t, routes, r.aggregateRoutes(routes))
+
+	// Lengths beyond the host length of the family are rejected.
+	require.ErrorContains(t, r.SetRouteAggregation(33, -1), "exceeds the host length of 32")
+	require.ErrorContains(t, r.SetRouteAggregation(-1, 129), "exceeds the host length of 128")
+	require.Equal(t, routes, r.aggregateRoutes(routes))
+
+	// Adjacent host routes collapse into one aggregate per covered range;
+	// non-host routes are left alone.
+	require.NoError(t, r.SetRouteAggregation(24, -1))
+	require.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("192.168.0.0/24"),
+		netip.MustParsePrefix("192.168.1.0/24"),
+		netip.MustParsePrefix("10.0.0.0/16"),
+		netip.MustParsePrefix("fd00:192:168::1/128"),
+		netip.MustParsePrefix("fd00:192:168::2/128"),
+	}, r.aggregateRoutes(routes))
+
+	// Each family aggregates with its own length.
+	require.NoError(t, r.SetRouteAggregation(24, 64))
+	require.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("192.168.0.0/24"),
+		netip.MustParsePrefix("192.168.1.0/24"),
+		netip.MustParsePrefix("10.0.0.0/16"),
+		netip.MustParsePrefix("fd00:192:168::/64"),
+	}, r.aggregateRoutes(routes))
+}
+
+func TestAnnouncedPathForNLRI(t *testing.T) {
+	svcA := resource.Key{Name: "svc-a", Namespace: "default"}
+	svcB := resource.Key{Name: "svc-b", Namespace: "default"}
+	shared := types.NewPathForPrefix(netip.MustParsePrefix("192.168.0.0/24"))
+	meta := LBServiceReconcilerMetadata{
+		svcA: {shared},
+		svcB: {shared},
+	}
+
+	// The aggregate is still referenced by svc-b after svc-a is skipped.
+	require.Equal(t, shared, announcedPathForNLRI(meta, svcA, shared.NLRI.String()))
+
+	// Once svc-b is gone too, nothing references it anymore.
+	delete(meta, svcB)
+	require.Nil(t, announcedPathForNLRI(meta, svcA, shared.NLRI.String()))
+}
+
+func TestLabelSetCache(t *testing.T) {
+	svc := &slim_corev1.Service{
+		ObjectMeta: slim_metav1.ObjectMeta{
+			Name:            "svc-1",
+			Namespace:       "default",
+			UID:             "uid-1",
+			ResourceVersion: "1",
+			Labels:          map[string]string{"color": "blue"},
+		},
+	}
+
+	r := &ServiceReconciler{}
+	first := r.labelSetFor(svc)
+	require.Equal(t, "blue", first.Get("color"))
+	require.Equal(t, "svc-1", first.Get("io.kubernetes.service.name"))
+
+	// An unchanged service is served from the cache.
+	require.Equal(t, fmt.Sprintf("%p", first), fmt.Sprintf("%p", r.labelSetFor(svc)))
+
+	// A label update bumps the resource version and invalidates the entry.
+	svc = svc.DeepCopy()
+	svc.ResourceVersion = "2"
+	svc.Labels["color"] = "red"
+	require.Equal(t, "red", r.labelSetFor(svc).Get("color"))
+
+	// Withdrawal drops the entry.
+	sc := &instance.ServerWithConfig{ReconcilerMetadata: make(map[string]any)}
+	require.NoError(t, r.withdrawService(context.Background(), sc, resource.NewKey(svc)))
+	require.NotContains(t, r.svcLabelCache, resource.NewKey(svc))
+}
+
+// BenchmarkLabelSetFor reconciles one service's label set against many
+// advertisements, comparing the cached path with recomputing it per call.
+func BenchmarkLabelSetFor(b *testing.B) {
+	const advertisements = 50
+
+	svc := &slim_corev1.Service{
+		ObjectMeta: slim_metav1.ObjectMeta{
+			Name:            "svc-1",
+			Namespace:       "default",
+			UID:             "uid-1",
+			ResourceVersion: "1",
+			Labels:          map[string]string{"color": "blue", "app": "demo"},
+		},
+	}
+
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < advertisements; j++ {
+				serviceLabelSet(svc)
+			}
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		r := &ServiceReconciler{}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < advertisements; j++ {
+				r.labelSetFor(svc)
+			}
+		}
+	})
+}
+
+func TestClusterIPReadinessGate(t *testing.T) {
+	svc := &slim_corev1.Service{
+		ObjectMeta: slim_metav1.ObjectMeta{Name: "svc-1", Namespace: "default"},
+		Spec: slim_corev1.ServiceSpec{
+			Type:       slim_corev1.ServiceTypeClusterIP,
+			ClusterIP:  "192.168.0.1",
+			ClusterIPs: []string{"192.168.0.1"},
+		},
+	}
+	clusterIPRoutes := []netip.Prefix{netip.MustParsePrefix("192.168.0.1/32")}
+
+	mkEps := func(backends map[cmtypes.AddrCluster]*k8s.Backend) *k8s.Endpoints {
+		return &k8s.Endpoints{
+			ObjectMeta: slim_metav1.ObjectMeta{Name: "svc-1-eps", Namespace: "default"},
+			EndpointSliceID: k8s.EndpointSliceID{
+				ServiceID:         k8s.ServiceID{Name: "svc-1", Namespace: "default"},
+				EndpointSliceName: "svc-1-eps",
+			},
+			Backends: backends,
+		}
+	}
+
+	newReconciler := func(eps *k8s.Endpoints) *ServiceReconciler {
+		diffstore := store.NewFakeDiffStore[*slim_corev1.Service]()
+		diffstore.Upsert(svc)
+		epDiffStore := store.NewFakeDiffStore[*k8s.Endpoints]()
+		if eps != nil {
+			epDiffStore.Upsert(eps)
+		}
+		return NewServiceReconciler(diffstore, epDiffStore).Reconciler.(*ServiceReconciler)
+	}
+
+	// A ready backend on any node keeps the ClusterIP advertised.
+	r := newReconciler(mkEps(map[cmtypes.AddrCluster]*k8s.Backend{
+		cmtypes.MustParseAddrCluster("10.0.0.1"): {NodeName: "node2"},
+	}))
+	r.SetClusterIPReadinessGate(true)
+	var err error
+	r.readyServices, err = r.populateReadyServices()
+	require.NoError(t, err)
+	require.Equal(t, clusterIPRoutes, r.clusterIPDesiredRoutes(svc, localServices{}))
+
+	// Only terminating backends left: the route is withdrawn.
+	r = newReconciler(mkEps(map[cmtypes.AddrCluster]*k8s.Backend{
+		cmtypes.MustParseAddrCluster("10.0.0.1"): {NodeName: "node2", Terminating: true},
+	}))
+	r.SetClusterIPReadinessGate(true)
+	r.readyServices, err = r.populateReadyServices()
+	require.NoError(t, err)
+	require.Empty(t, r.clusterIPDesiredRoutes(svc, localServices{}))
+
+	// No endpoints at all: the route is withdrawn.
+	r = newReconciler(nil)
+	r.SetClusterIPReadinessGate(true)
+	r.readyServices, err = r.populateReadyServices()
+	require.NoError(t, err)
+	require.Empty(t, r.clusterIPDesiredRoutes(svc, localServices{}))
+
+	// Gate disabled: the existence of the service is enough.
+	r = newReconciler(nil)
+	require.Equal(t, clusterIPRoutes, r.clusterIPDesiredRoutes(svc, localServices{}))
+}
@@ -9,8 +9,10 @@ import (
 	"fmt"
 	"maps"
 	"net/netip"
+	"sort"
 
 	"github.com/cilium/hive/cell"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -24,7 +26,9 @@ import (
 	slim_corev1 "github.com/cilium/cilium/pkg/k8s/slim/k8s/api/core/v1"
 	"github.com/cilium/cilium/pkg/k8s/slim/k8s/apis/labels"
 	slim_metav1 "github.com/cilium/cilium/pkg/k8s/slim/k8s/apis/meta/v1"
+	"github.com/cilium/cilium/pkg/metrics"
 	ciliumslices "github.com/cilium/cilium/pkg/slices"
+	"github.com/cilium/cilium/pkg/time"
 )
 
 type ServiceReconcilerOut struct {
@@ -41,6 +45,8 @@ type ServiceReconcilerIn struct {
 	LBIPPoolStore store.BGPCPResourceStore[*v2alpha1.CiliumLoadBalancerIPPool]
 	SvcDiffStore  store.DiffStore[*slim_corev1.Service]
 	EPDiffStore   store.DiffStore[*k8s.Endpoints]
+
+	Registry *metrics.Registry `optional:"true"`
 }
 
 type ServiceReconciler struct {
@@ -49,6 +55,14 @@ type ServiceReconciler struct {
 	lbPoolStore  store.BGPCPResourceStore[*v2alpha1.CiliumLoadBalancerIPPool]
 	svcDiffStore store.DiffStore[*slim_corev1.Service]
 	epDiffStore  store.DiffStore[*k8s.Endpoints]
+
+	// reconcileDuration observes the duration of reconcileServices per
+	// instance and reconcile kind ("full" or "diff"), so that operators can
+	// see when advertisement changes make full reconciliations expensive.
+	reconcileDuration *prometheus.HistogramVec
+	// reconcileSvcCount reports the number of services processed by the most
+	// recent reconciliation per instance and reconcile kind.
+	reconcileSvcCount *prometheus.GaugeVec
 }
 
 func NewServiceReconciler(in ServiceReconcilerIn) ServiceReconcilerOut {
@@ -56,14 +70,34 @@ func NewServiceReconciler(in ServiceReconcilerIn) ServiceReconcilerOut {
 		return ServiceReconcilerOut{}
 	}
 
+	r := &ServiceReconciler{
+		logger:       in.Logger,
+		peerAdvert:   in.PeerAdvert,
+		lbPoolStore:  in.LBIPPoolStore,
+		svcDiffStore: in.SvcDiffStore,
+		epDiffStore:  in.EPDiffStore,
+		reconcileDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "bgp_control_plane",
+			Name:      "service_reconcile_duration_seconds",
+			Help:      "Duration of a service reconciliation run, by instance and reconcile kind",
+		}, []string{"instance", "kind"}),
+		reconcileSvcCount: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "bgp_control_plane",
+			Name:      "service_reconcile_services",
+			Help:      "Number of services processed by the most recent service reconciliation, by instance and reconcile kind",
+		}, []string{"instance", "kind"}),
+	}
+
+	// The registry is absent in unit tests constructing the reconciler
+	// directly, the metrics are then collected but not exposed.
+	if in.Registry != nil {
+		in.Registry.MustRegister(r.reconcileDuration, r.reconcileSvcCount)
+	}
+
 	return ServiceReconcilerOut{
-		Reconciler: &ServiceReconciler{
-			logger:       in.Logger,
-			peerAdvert:   in.PeerAdvert,
-			lbPoolStore:  in.LBIPPoolStore,
-			svcDiffStore: in.SvcDiffStore,
-			epDiffStore:  in.EPDiffStore,
-		},
+		Reconciler: r,
 	}
 }
 
@@ -121,26 +155,35 @@ func (r *ServiceReconciler) Reconcile(ctx context.Context, p ReconcileParams) er
 	// must be done before reconciling paths and policies since it sets metadata with latest desiredPeerAdverts
 	reqFullReconcile := r.modifiedServiceAdvertisements(p, desiredPeerAdverts)
 
-	return r.reconcileServices(ctx, p, ls, reqFullReconcile)
+	kind := "diff"
+	if reqFullReconcile {
+		kind = "full"
+	}
+
+	start := time.Now()
+	processed, err := r.reconcileServices(ctx, p, ls, reqFullReconcile)
+	r.reconcileDuration.WithLabelValues(p.DesiredConfig.Name, kind).Observe(time.Since(start).Seconds())
+	r.reconcileSvcCount.WithLabelValues(p.DesiredConfig.Name, kind).Set(float64(processed))
+
+	return err
 }
 
-func (r *ServiceReconciler) reconcileServices(ctx context.Context, p ReconcileParams, ls sets.Set[resource.Key], fullReconcile bool) error {
+func (r *ServiceReconciler) reconcileServices(ctx context.Context, p ReconcileParams, ls sets.Set[resource.Key], fullReconcile bool) (processed int, err error) {
 	var desiredSvcRoutePolicies ResourceRoutePolicyMap
 	var desiredSvcPaths ResourceAFPathsMap
-	var err error
 
 	if fullReconcile {
 		r.logger.Debug("performing all services reconciliation")
 
 		desiredSvcRoutePolicies, err = r.getAllRoutePolicies(p, ls)
 		if err != nil {
-			return err
+			return 0, err
 		}
 
 		// BGP configuration for service advertisement changed, we should reconcile all services.
 		desiredSvcPaths, err = r.getAllPaths(p, ls)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	} else {
 		r.logger.Debug("performing modified services reconciliation")
@@ -149,40 +192,42 @@ func (r *ServiceReconciler) reconcileServices(ctx context.Context, p ReconcilePa
 		// Note : we should only call svc diff only once in a reconcile loop.
 		toReconcile, toWithdraw, err := r.diffReconciliationServiceList()
 		if err != nil {
-			return err
+			return 0, err
 		}
 
 		desiredSvcRoutePolicies, err = r.getDiffRoutePolicies(p, toReconcile, toWithdraw, ls)
 		if err != nil {
-			return err
+			return 0, err
 		}
 
 		// BGP configuration is unchanged, only reconcile modified services.
 		desiredSvcPaths, err = r.getDiffPaths(p, toReconcile, toWithdraw, ls)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	}
 
+	processed = len(desiredSvcPaths)
+
 	// reconcile service route policies
 	err = r.reconcileSvcRoutePolicies(ctx, p, desiredSvcRoutePolicies)
 	if err != nil {
-		return fmt.Errorf("failed to reconcile service route policies: %w", err)
+		return processed, fmt.Errorf("failed to reconcile service route policies: %w", err)
 	}
 
 	// reconcile LB pool route policies, we always do full reconciliation for LB pool route policies
 	err = r.reconcileLBIPPoolRoutePolicies(ctx, p)
 	if err != nil {
-		return fmt.Errorf("failed to reconcile lb route policies: %w", err)
+		return processed, fmt.Errorf("failed to reconcile lb route policies: %w", err)
 	}
 
 	// reconcile service paths
 	err = r.reconcilePaths(ctx, p, desiredSvcPaths)
 	if err != nil {
-		return fmt.Errorf("failed to reconcile service paths: %w", err)
+		return processed, fmt.Errorf("failed to reconcile service paths: %w", err)
 	}
 
-	return nil
+	return processed, nil
 }
 
 func (r *ServiceReconciler) reconcileLBIPPoolRoutePolicies(ctx context.Context, p ReconcileParams) error {
@@ -358,6 +403,12 @@ func (r *ServiceReconciler) getDiffRoutePolicies(p ReconcileParams, toUpdate []*
 }
 
 func (r *ServiceReconciler) getDesiredSvcRoutePolicies(p ReconcileParams, svc *slim_corev1.Service, ls sets.Set[resource.Key]) (RoutePolicyMap, error) {
+	// refuse to generate policies when peers share an address, as their
+	// policies would silently overwrite each other
+	if err := r.checkDuplicatePeerAddresses(p); err != nil {
+		return nil, err
+	}
+
 	// get cluster IP route policy
 	desiredClusterRoutePolicies, err := r.getClusterIPRoutePolicies(p, svc, ls)
 	if err != nil {
@@ -378,6 +429,35 @@ func (r *ServiceReconciler) getDesiredSvcRoutePolicies(p ReconcileParams, svc *s
 	return desiredSvcRPs, nil
 }
 
+// checkDuplicatePeerAddresses guards against multiple peers configured with the
+// same peer address. Route policies match on the neighbor address, so policies
+// generated for peers sharing an address would overwrite each other during
+// reconciliation. This is a misconfiguration which should be surfaced to the
+// user instead of being silently accepted.
+func (r *ServiceReconciler) checkDuplicatePeerAddresses(p ReconcileParams) error {
+	peers := make([]string, 0, len(r.getMetadata(p.BGPInstance).ServiceAdvertisements))
+	for peer := range r.getMetadata(p.BGPInstance).ServiceAdvertisements {
+		peers = append(peers, peer)
+	}
+	sort.Strings(peers) // deterministic error message
+
+	seen := make(map[netip.Addr]string, len(peers))
+	for _, peer := range peers {
+		peerAddr, err := GetPeerAddressFromConfig(p.DesiredConfig, peer)
+		if err != nil {
+			// the peer address is resolved again during policy generation,
+			// which will report the error with more context
+			continue
+		}
+		if other, exists := seen[peerAddr]; exists {
+			return fmt.Errorf("peers %s and %s share the same peer address %s", other, peer, peerAddr)
+		}
+		seen[peerAddr] = peer
+	}
+
+	return nil
+}
+
 func (r *ServiceReconciler) reconcilePaths(ctx context.Context, p ReconcileParams, desiredSvcPaths ResourceAFPathsMap) error {
 	var err error
 	metadata := r.getMetadata(p.BGPInstance)
@@ -412,6 +492,41 @@ func (r *ServiceReconciler) reconcilePaths(ctx context.Context, p ReconcileParam
 	return err
 }
 
+// WithdrawAll withdraws all currently advertised service routes of the given
+// instance while keeping it configured, e.g. for a maintenance drain of the
+// node. Each tracked service is reconciled towards an empty set of desired
+// paths; the remaining metadata (advertisements and route policies) is left
+// intact, so a subsequent full reconciliation re-advertises the routes.
+func (r *ServiceReconciler) WithdrawAll(ctx context.Context, i *instance.BGPInstance) error {
+	var err error
+	metadata := r.getMetadata(i)
+	logger := r.logger
+	if i.Config != nil {
+		logger = logger.WithField(types.InstanceLogField, i.Config.Name)
+	}
+	for svc, currentAFPaths := range metadata.ServicePaths {
+		updatedAFPaths, rErr := ReconcileAFPaths(&ReconcileAFPathsParams{
+			Logger:       logger,
+			Ctx:          ctx,
+			Router:       i.Router,
+			DesiredPaths: nil,
+			CurrentPaths: currentAFPaths,
+		})
+
+		if rErr == nil {
+			delete(metadata.ServicePaths, svc)
+		} else {
+			// track the paths which remain advertised after the error, so
+			// that a retry withdraws only those.
+			metadata.ServicePaths[svc] = updatedAFPaths
+		}
+		err = errors.Join(err, rErr)
+	}
+	r.setMetadata(i, metadata)
+
+	return err
+}
+
 // modifiedServiceAdvertisements compares local advertisement state with desiredPeerAdverts, if they differ, it updates the local state and returns true
 // for full reconciliation.
 func (r *ServiceReconciler) modifiedServiceAdvertisements(p ReconcileParams, desiredPeerAdverts PeerAdvertisements) bool {
@@ -686,6 +801,7 @@ func (r *ServiceReconciler) getExternalIPPaths(svc *slim_corev1.Service, ls sets
 		}
 		addr, err := netip.ParseAddr(extIP)
 		if err != nil {
+			r.logger.WithError(err).Warnf("not advertising unparsable external IP %s of service %s/%s", extIP, svc.Namespace, svc.Name)
 			continue
 		}
 		desiredRoutes = append(desiredRoutes, netip.PrefixFrom(addr, addr.BitLen()))
@@ -716,6 +832,7 @@ func (r *ServiceReconciler) getClusterIPPaths(svc *slim_corev1.Service, ls sets.
 	for _, ip := range sets.List(ips) {
 		addr, err := netip.ParseAddr(ip)
 		if err != nil {
+			r.logger.WithError(err).Warnf("not advertising unparsable cluster IP %s of service %s/%s", ip, svc.Namespace, svc.Name)
 			continue
 		}
 		desiredRoutes = append(desiredRoutes, netip.PrefixFrom(addr, addr.BitLen()))
@@ -744,6 +861,7 @@ func (r *ServiceReconciler) getLBSvcPaths(svc *slim_corev1.Service, ls sets.Set[
 		}
 		addr, err := netip.ParseAddr(ingress.IP)
 		if err != nil {
+			r.logger.WithError(err).Warnf("not advertising unparsable load balancer ingress IP %s of service %s/%s", ingress.IP, svc.Namespace, svc.Name)
 			continue
 		}
 		desiredRoutes = append(desiredRoutes, netip.PrefixFrom(addr, addr.BitLen()))
@@ -774,6 +774,50 @@ func Test_ServiceLBReconciler(t *testing.T) {
 	}
 }
 
+// Test_ServiceWithdrawAll tests withdrawing all advertised service routes of
+// an instance while keeping its configuration and advertisement metadata.
+func Test_ServiceWithdrawAll(t *testing.T) {
+	req := require.New(t)
+
+	params := ServiceReconcilerIn{
+		Logger: serviceIPPoolTestLogger,
+		PeerAdvert: NewCiliumPeerAdvertisement(
+			PeerAdvertisementIn{
+				Logger:          podCIDRTestLogger,
+				PeerConfigStore: store.InitMockStore[*v2alpha1.CiliumBGPPeerConfig]([]*v2alpha1.CiliumBGPPeerConfig{redPeerConfig}),
+				AdvertStore: store.InitMockStore[*v2alpha1.CiliumBGPAdvertisement]([]*v2alpha1.CiliumBGPAdvertisement{
+					redSvcAdvertWithAdvertisements(lbSvcAdvertWithSelector(redSvcSelector)),
+				}),
+			}),
+		LBIPPoolStore: store.InitMockStore[*v2alpha1.CiliumLoadBalancerIPPool]([]*v2alpha1.CiliumLoadBalancerIPPool{redLBPool}),
+		SvcDiffStore:  store.InitFakeDiffStore[*slim_corev1.Service]([]*slim_corev1.Service{redLBSvc}),
+		EPDiffStore:   store.InitFakeDiffStore[*k8s.Endpoints](nil),
+	}
+
+	svcReconciler := NewServiceReconciler(params).Reconciler.(*ServiceReconciler)
+	testBGPInstance := instance.NewFakeBGPInstance()
+
+	err := svcReconciler.Reconcile(context.Background(), ReconcileParams{
+		BGPInstance:   testBGPInstance,
+		DesiredConfig: testBGPInstanceConfig,
+		CiliumNode:    testCiliumNodeConfig,
+	})
+	req.NoError(err)
+
+	before := svcReconciler.getMetadata(testBGPInstance)
+	req.NotEmpty(before.ServicePaths)
+	req.NotEmpty(before.ServiceAdvertisements)
+
+	req.NoError(svcReconciler.WithdrawAll(context.Background(), testBGPInstance))
+
+	// All paths are withdrawn, the remaining metadata is left intact so a
+	// subsequent full reconciliation re-advertises the routes.
+	after := svcReconciler.getMetadata(testBGPInstance)
+	req.Empty(after.ServicePaths)
+	req.Equal(before.ServiceAdvertisements, after.ServiceAdvertisements)
+	req.Equal(before.LBPoolRoutePolicies, after.LBPoolRoutePolicies)
+}
+
 // Test_ServiceExternalIPReconciler tests reconciliation of cluster service with external IP
 func Test_ServiceExternalIPReconciler(t *testing.T) {
 	logrus.SetLevel(logrus.DebugLevel)
@@ -1230,6 +1274,45 @@ func Test_ServiceClusterIPReconciler(t *testing.T) {
 	}
 }
 
+// Test_ServicePeerAddressConflict validates that route policy generation is
+// refused with a clear error when two peers are configured with the same peer
+// address, instead of one peer's policies silently overwriting the other's.
+func Test_ServicePeerAddressConflict(t *testing.T) {
+	req := require.New(t)
+
+	conflictingConfig := testBGPInstanceConfig.DeepCopy()
+	bluePeer := conflictingConfig.Peers[0].DeepCopy()
+	bluePeer.Name = "blue-peer-65001"
+	conflictingConfig.Peers = append(conflictingConfig.Peers, *bluePeer)
+
+	params := ServiceReconcilerIn{
+		Logger: serviceIPPoolTestLogger,
+		PeerAdvert: NewCiliumPeerAdvertisement(
+			PeerAdvertisementIn{
+				Logger:          podCIDRTestLogger,
+				PeerConfigStore: store.InitMockStore[*v2alpha1.CiliumBGPPeerConfig]([]*v2alpha1.CiliumBGPPeerConfig{redPeerConfig}),
+				AdvertStore: store.InitMockStore[*v2alpha1.CiliumBGPAdvertisement]([]*v2alpha1.CiliumBGPAdvertisement{
+					redSvcAdvertWithAdvertisements(clusterIPSvcAdvertWithSelector(redSvcSelector)),
+				}),
+			}),
+		LBIPPoolStore: store.InitMockStore[*v2alpha1.CiliumLoadBalancerIPPool](nil),
+		SvcDiffStore:  store.InitFakeDiffStore[*slim_corev1.Service]([]*slim_corev1.Service{redClusterSvc}),
+		EPDiffStore:   store.InitFakeDiffStore[*k8s.Endpoints](nil),
+	}
+
+	svcReconciler := NewServiceReconciler(params).Reconciler.(*ServiceReconciler)
+	testBGPInstance := instance.NewFakeBGPInstance()
+
+	err := svcReconciler.Reconcile(context.Background(), ReconcileParams{
+		BGPInstance:   testBGPInstance,
+		DesiredConfig: conflictingConfig,
+		CiliumNode:    testCiliumNodeConfig,
+	})
+	req.ErrorContains(err, "blue-peer-65001")
+	req.ErrorContains(err, "red-peer-65001")
+	req.ErrorContains(err, "share the same peer address 10.10.10.1")
+}
+
 // Test_ServiceAndAdvertisementModifications is a step test, in which each step modifies the advertisement or service parameters.
 func Test_ServiceAndAdvertisementModifications(t *testing.T) {
 	logrus.SetLevel(logrus.DebugLevel)
@@ -4,6 +4,7 @@
 package types
 
 import (
+	"fmt"
 	"net/netip"
 	"slices"
 
@@ -75,6 +76,29 @@ func NewPathForPrefix(prefix netip.Prefix) (path *Path) {
 	return
 }
 
+// NewPathForPrefixWithNextHop returns a Path like NewPathForPrefix, but with
+// the next hop set to the provided address instead of leaving its selection to
+// the underlying BGP implementation. This is used for advertisements that
+// should direct traffic to a gateway other than the peering address. An error
+// is returned if the next hop's address family does not match the prefix's.
+func NewPathForPrefixWithNextHop(prefix netip.Prefix, nextHop netip.Addr) (*Path, error) {
+	if prefix.Addr().Is4() != nextHop.Is4() {
+		return nil, fmt.Errorf("next hop %s does not match the address family of prefix %s", nextHop, prefix)
+	}
+
+	path := NewPathForPrefix(prefix)
+	for i, attr := range path.PathAttributes {
+		switch attr.(type) {
+		case *bgp.PathAttributeNextHop:
+			path.PathAttributes[i] = bgp.NewPathAttributeNextHop(nextHop.String())
+		case *bgp.PathAttributeMpReachNLRI:
+			path.PathAttributes[i] = bgp.NewPathAttributeMpReachNLRI(nextHop.String(), []bgp.AddrPrefixInterface{path.NLRI})
+		}
+	}
+
+	return path, nil
+}
+
 // DeepEqual is a manually created deepequal function, deeply comparing the receiver with another.
 // It compares fields with types that do not implement the `DeepEqual` method
 // and calls the generated private `deepEqual` method which compares the rest of the fields.
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package types
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPathForPrefixWithNextHop(t *testing.T) {
+	// IPv4 prefix with an IPv4 next hop override.
+	path, err := NewPathForPrefixWithNextHop(netip.MustParsePrefix("10.0.0.0/24"), netip.MustParseAddr("192.168.0.1"))
+	require.NoError(t, err)
+	var nextHop string
+	for _, attr := range path.PathAttributes {
+		if nh, ok := attr.(*bgp.PathAttributeNextHop); ok {
+			nextHop = nh.Value.String()
+		}
+	}
+	require.Equal(t, "192.168.0.1", nextHop)
+
+	// IPv6 prefix with an IPv6 next hop override, carried in MP_REACH_NLRI.
+	path, err = NewPathForPrefixWithNextHop(netip.MustParsePrefix("fd00::/64"), netip.MustParseAddr("fd00::1"))
+	require.NoError(t, err)
+	nextHop = ""
+	for _, attr := range path.PathAttributes {
+		if mp, ok := attr.(*bgp.PathAttributeMpReachNLRI); ok {
+			nextHop = mp.Nexthop.String()
+		}
+	}
+	require.Equal(t, "fd00::1", nextHop)
+
+	// Mismatched address families are rejected.
+	_, err = NewPathForPrefixWithNextHop(netip.MustParsePrefix("10.0.0.0/24"), netip.MustParseAddr("fd00::1"))
+	require.Error(t, err)
+	_, err = NewPathForPrefixWithNextHop(netip.MustParsePrefix("fd00::/64"), netip.MustParseAddr("192.168.0.1"))
+	require.Error(t, err)
+}
@@ -561,6 +561,35 @@ func (m *Map) open() error {
 	return nil
 }
 
+// OpenReadOnly opens the pinned BPF map with a read-only file descriptor.
+// Lookups and dumps work as usual, while any write issued through the map
+// fails at the syscall level, making the handle safe for inspection tooling
+// running next to a live agent.
+func (m *Map) OpenReadOnly() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.m != nil {
+		return nil
+	}
+
+	if err := m.setPathIfUnset(); err != nil {
+		return err
+	}
+
+	em, err := ebpf.LoadPinnedMap(m.path, &ebpf.LoadPinOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("loading pinned map %s read-only: %w", m.path, err)
+	}
+
+	m.updateMetrics()
+	registerMap(m.path, m)
+
+	m.m = em
+
+	return nil
+}
+
 func (m *Map) Close() error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
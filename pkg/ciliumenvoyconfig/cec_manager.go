@@ -8,7 +8,10 @@ import (
 	"fmt"
 	"slices"
 	"strconv"
+	"strings"
 
+	envoy_config_core "github.com/cilium/proxy/go/envoy/config/core/v3"
+	envoy_config_tls "github.com/cilium/proxy/go/envoy/extensions/transport_sockets/tls/v3"
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -18,6 +21,7 @@ import (
 	"github.com/cilium/cilium/pkg/k8s/resource"
 	slim_corev1 "github.com/cilium/cilium/pkg/k8s/slim/k8s/api/core/v1"
 	"github.com/cilium/cilium/pkg/loadbalancer"
+	"github.com/cilium/cilium/pkg/logging/logfields"
 	"github.com/cilium/cilium/pkg/policy"
 	"github.com/cilium/cilium/pkg/policy/api"
 	"github.com/cilium/cilium/pkg/service"
@@ -26,8 +30,11 @@ import (
 
 type ciliumEnvoyConfigManager interface {
 	addCiliumEnvoyConfig(cecObjectMeta metav1.ObjectMeta, cecSpec *ciliumv2.CiliumEnvoyConfigSpec) error
+	addCiliumEnvoyConfigs(configs map[resource.Key]*config) map[resource.Key]error
 	updateCiliumEnvoyConfig(oldCECObjectMeta metav1.ObjectMeta, oldCECSpec *ciliumv2.CiliumEnvoyConfigSpec, newCECObjectMeta metav1.ObjectMeta, newCECSpec *ciliumv2.CiliumEnvoyConfigSpec) error
 	deleteCiliumEnvoyConfig(cecObjectMeta metav1.ObjectMeta, cecSpec *ciliumv2.CiliumEnvoyConfigSpec) error
+	deleteCiliumEnvoyConfigResources(cecObjectMeta metav1.ObjectMeta, cecSpec *ciliumv2.CiliumEnvoyConfigSpec, resources *envoy.Resources) error
+	parseConfigResources(cecObjectMeta metav1.ObjectMeta, cecSpec *ciliumv2.CiliumEnvoyConfigSpec) (envoy.Resources, error)
 	syncCiliumEnvoyConfigService(name string, namespace string, cecSpec *ciliumv2.CiliumEnvoyConfigSpec) error
 }
 
@@ -43,6 +50,10 @@ type cecManager struct {
 
 	envoyConfigTimeout time.Duration
 
+	// serviceMissingWarning controls whether a warning is emitted when a
+	// CEC references a k8s service that does not (yet) exist.
+	serviceMissingWarning bool
+
 	services  resource.Resource[*slim_corev1.Service]
 	endpoints resource.Resource[*k8s.Endpoints]
 }
@@ -54,23 +65,137 @@ func newCiliumEnvoyConfigManager(logger logrus.FieldLogger,
 	backendSyncer *envoyServiceBackendSyncer,
 	resourceParser *cecResourceParser,
 	envoyConfigTimeout time.Duration,
+	serviceMissingWarning bool,
 	services resource.Resource[*slim_corev1.Service],
 	endpoints resource.Resource[*k8s.Endpoints],
 ) *cecManager {
 	return &cecManager{
-		logger:             logger,
-		policyUpdater:      policyUpdater,
-		serviceManager:     serviceManager,
-		xdsServer:          xdsServer,
-		backendSyncer:      backendSyncer,
-		resourceParser:     resourceParser,
-		envoyConfigTimeout: envoyConfigTimeout,
-		services:           services,
-		endpoints:          endpoints,
+		logger:                logger,
+		policyUpdater:         policyUpdater,
+		serviceManager:        serviceManager,
+		xdsServer:             xdsServer,
+		backendSyncer:         backendSyncer,
+		resourceParser:        resourceParser,
+		envoyConfigTimeout:    envoyConfigTimeout,
+		serviceMissingWarning: serviceMissingWarning,
+		services:              services,
+		endpoints:             endpoints,
 	}
 }
 
+// envoyConfigTimeoutAnnotation overrides the xDS update timeout for a single
+// CEC, e.g. for very large resource sets that exceed the global default. The
+// value must parse as a duration; it is capped at maxEnvoyConfigTimeout.
+const envoyConfigTimeoutAnnotation = "cec.cilium.io/config-timeout"
+
+// maxEnvoyConfigTimeout caps per-CEC timeout overrides so that a mistyped
+// annotation cannot block config application near-indefinitely.
+const maxEnvoyConfigTimeout = 10 * time.Minute
+
+// configTimeout returns the xDS update timeout to use for the given config,
+// honoring the per-CEC annotation override and falling back to the global
+// default. Invalid or non-positive values are logged and ignored.
+func (r *cecManager) configTimeout(cecObjectMeta *metav1.ObjectMeta) time.Duration {
+	v, ok := cecObjectMeta.GetAnnotations()[envoyConfigTimeoutAnnotation]
+	if !ok {
+		return r.envoyConfigTimeout
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		r.logger.WithError(err).
+			WithField(logfields.K8sNamespace, cecObjectMeta.GetNamespace()).
+			WithField(logfields.CiliumEnvoyConfigName, cecObjectMeta.GetName()).
+			Warnf("Ignoring invalid %s annotation value %q", envoyConfigTimeoutAnnotation, v)
+		return r.envoyConfigTimeout
+	}
+
+	return min(d, maxEnvoyConfigTimeout)
+}
+
 func (r *cecManager) addCiliumEnvoyConfig(cecObjectMeta metav1.ObjectMeta, cecSpec *ciliumv2.CiliumEnvoyConfigSpec) error {
+	resources, err := r.parseConfigResources(cecObjectMeta, cecSpec)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.configTimeout(&cecObjectMeta))
+	defer cancel()
+	if err := r.xdsServer.UpsertEnvoyResources(ctx, resources); err != nil {
+		return fmt.Errorf("failed to upsert envoy resources: %w", err)
+	}
+
+	return r.applyConfigRedirects(cecObjectMeta, cecSpec, resources)
+}
+
+// addCiliumEnvoyConfigs applies the given set of configs, accumulating their
+// parsed resources into a single xDS update instead of pushing one update per
+// config. Used when a node label change makes many configs applicable at
+// once. A per-config error map is returned; if the combined update fails, it
+// is reverted as a whole and the configs are re-applied individually, so that
+// only the resources of the actually failing configs are rolled back.
+func (r *cecManager) addCiliumEnvoyConfigs(configs map[resource.Key]*config) map[resource.Key]error {
+	errs := map[resource.Key]error{}
+
+	parsed := map[resource.Key]envoy.Resources{}
+	var merged envoy.Resources
+	for key, cfg := range configs {
+		resources, err := r.parseConfigResources(cfg.meta, cfg.spec)
+		if err != nil {
+			errs[key] = err
+			continue
+		}
+		parsed[key] = resources
+
+		merged.Listeners = append(merged.Listeners, resources.Listeners...)
+		merged.Secrets = append(merged.Secrets, resources.Secrets...)
+		merged.Routes = append(merged.Routes, resources.Routes...)
+		merged.Clusters = append(merged.Clusters, resources.Clusters...)
+		merged.Endpoints = append(merged.Endpoints, resources.Endpoints...)
+		for name, cb := range resources.PortAllocationCallbacks {
+			if merged.PortAllocationCallbacks == nil {
+				merged.PortAllocationCallbacks = map[string]func(context.Context) error{}
+			}
+			merged.PortAllocationCallbacks[name] = cb
+		}
+	}
+
+	if len(parsed) == 0 {
+		return errs
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.envoyConfigTimeout)
+	defer cancel()
+	if err := r.xdsServer.UpsertEnvoyResources(ctx, merged); err != nil {
+		r.logger.WithError(err).
+			Warn("Failed to apply combined xDS update, re-applying configs individually")
+		for key, cfg := range configs {
+			if _, ok := parsed[key]; !ok {
+				continue
+			}
+			if err := r.addCiliumEnvoyConfig(cfg.meta, cfg.spec); err != nil {
+				errs[key] = err
+			}
+		}
+		return errs
+	}
+
+	for key, cfg := range configs {
+		resources, ok := parsed[key]
+		if !ok {
+			continue
+		}
+		if err := r.applyConfigRedirects(cfg.meta, cfg.spec, resources); err != nil {
+			errs[key] = err
+		}
+	}
+
+	return errs
+}
+
+// parseConfigResources parses the Envoy resources of the given config and
+// applies the config-level transformations (upstream TLS injection).
+func (r *cecManager) parseConfigResources(cecObjectMeta metav1.ObjectMeta, cecSpec *ciliumv2.CiliumEnvoyConfigSpec) (envoy.Resources, error) {
 	resources, err := r.resourceParser.parseResources(
 		cecObjectMeta.GetNamespace(),
 		cecObjectMeta.GetName(),
@@ -80,15 +205,18 @@ func (r *cecManager) addCiliumEnvoyConfig(cecObjectMeta metav1.ObjectMeta, cecSp
 		true,
 	)
 	if err != nil {
-		return fmt.Errorf("malformed Envoy config: %w", err)
+		return envoy.Resources{}, fmt.Errorf("malformed Envoy config: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), r.envoyConfigTimeout)
-	defer cancel()
-	if err := r.xdsServer.UpsertEnvoyResources(ctx, resources); err != nil {
-		return fmt.Errorf("failed to upsert envoy resources: %w", err)
-	}
+	r.injectUpstreamTLS(cecObjectMeta.GetNamespace(), cecObjectMeta.GetName(), cecSpec, resources)
 
+	return resources, nil
+}
+
+// applyConfigRedirects performs the post-upsert steps of adding a config:
+// registering the k8s service redirects and triggering a policy update if
+// listeners were added.
+func (r *cecManager) applyConfigRedirects(cecObjectMeta metav1.ObjectMeta, cecSpec *ciliumv2.CiliumEnvoyConfigSpec, resources envoy.Resources) error {
 	name := service.L7LBResourceName{Name: cecObjectMeta.Name, Namespace: cecObjectMeta.Namespace}
 	if err := r.addK8sServiceRedirects(name, cecSpec, resources); err != nil {
 		return fmt.Errorf("failed to redirect k8s services to Envoy in CEC Add: %w", err)
@@ -102,7 +230,7 @@ func (r *cecManager) addCiliumEnvoyConfig(cecObjectMeta metav1.ObjectMeta, cecSp
 		r.policyUpdater.TriggerPolicyUpdates(true, "Envoy Listeners added")
 	}
 
-	return err
+	return nil
 }
 
 func (r *cecManager) addK8sServiceRedirects(resourceName service.L7LBResourceName, spec *ciliumv2.CiliumEnvoyConfigSpec, resources envoy.Resources) error {
@@ -145,11 +273,23 @@ func (r *cecManager) addK8sServiceRedirects(resourceName service.L7LBResourceNam
 
 		// Tell service manager to redirect the service to the port
 		serviceName := getServiceName(resourceName, svc.Name, svc.Namespace, true)
+
+		// Warn about references to services that do not exist (yet). The
+		// redirect is still registered below, so that it becomes active as
+		// soon as the service appears.
+		if r.serviceMissingWarning {
+			if kSvc, err := r.getK8sService(svc.Name, svc.Namespace); err != nil {
+				r.logger.WithError(err).Warnf("Failed to look up service %s/%s referenced in CiliumEnvoyConfig %s", svc.Namespace, svc.Name, resourceName.Name)
+			} else if kSvc == nil {
+				r.logger.Warnf("Service %s/%s referenced in CiliumEnvoyConfig %s does not exist, the redirect activates once the service appears", svc.Namespace, svc.Name, resourceName.Name)
+			}
+		}
+
 		if err := r.serviceManager.RegisterL7LBServiceRedirect(serviceName, resourceName, proxyPort, svc.Ports); err != nil {
 			return err
 		}
 
-		if err := r.registerServiceSync(serviceName, resourceName, nil /* all ports */); err != nil {
+		if err := r.registerServiceSync(serviceName, resourceName, nil /* all ports */, ""); err != nil {
 			return err
 		}
 	}
@@ -163,7 +303,7 @@ func (r *cecManager) syncCiliumEnvoyConfigService(name string, namespace string,
 	for _, svc := range spec.BackendServices {
 		serviceName := getServiceName(resourceName, svc.Name, svc.Namespace, false)
 
-		if err := r.registerServiceSync(serviceName, resourceName, svc.Ports); err != nil {
+		if err := r.registerServiceSync(serviceName, resourceName, svc.Ports, svc.TLSSecret); err != nil {
 			return err
 		}
 
@@ -331,12 +471,14 @@ func (r *cecManager) updateCiliumEnvoyConfig(
 		return fmt.Errorf("malformed new Envoy config: %w", err)
 	}
 
+	r.injectUpstreamTLS(newCECObjectMeta.GetNamespace(), newCECObjectMeta.GetName(), newCECSpec, newResources)
+
 	name := service.L7LBResourceName{Name: oldCECObjectMeta.Name, Namespace: oldCECObjectMeta.Namespace}
 	if err := r.removeK8sServiceRedirects(name, oldCECSpec, newCECSpec, oldResources, newResources); err != nil {
 		return fmt.Errorf("failed to update k8s service redirects: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), r.envoyConfigTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), r.configTimeout(&newCECObjectMeta))
 	defer cancel()
 	if err := r.xdsServer.UpdateEnvoyResources(ctx, oldResources, newResources); err != nil {
 		return fmt.Errorf("failed to update Envoy resources: %w", err)
@@ -393,15 +535,26 @@ func (r *cecManager) removeK8sServiceRedirects(resourceName service.L7LBResource
 	}
 	removedBackendServices := []*ciliumv2.Service{}
 	for _, oldSvc := range oldSpec.BackendServices {
-		found := false
+		var persisted *ciliumv2.Service
 		for _, newSvc := range newSpec.BackendServices {
 			if newSvc.Name == oldSvc.Name && newSvc.Namespace == oldSvc.Namespace {
-				found = true
+				persisted = newSvc
 				break
 			}
 		}
-		if !found {
+		if persisted == nil {
 			removedBackendServices = append(removedBackendServices, oldSvc)
+			continue
+		}
+		// The backend service persists. When only its port set or TLS secret
+		// changed, refresh the registration in place instead of tearing the
+		// sync down and re-creating it, so that there is no window in which
+		// the service is unsynced.
+		if !slices.Equal(persisted.Ports, oldSvc.Ports) || persisted.TLSSecret != oldSvc.TLSSecret {
+			serviceName := getServiceName(resourceName, persisted.Name, persisted.Namespace, false)
+			if err := r.registerServiceSync(serviceName, resourceName, persisted.Ports, persisted.TLSSecret); err != nil {
+				return err
+			}
 		}
 	}
 	for _, oldSvc := range removedBackendServices {
@@ -426,9 +579,33 @@ func (r *cecManager) deleteCiliumEnvoyConfig(cecObjectMeta metav1.ObjectMeta, ce
 		false,
 	)
 	if err != nil {
-		return fmt.Errorf("parsing resources names failed: %w", err)
+		// The spec may no longer pass validation, e.g. when it was applied
+		// by an older version of the agent. Fall back to deleting the
+		// resource names derivable from the spec so that the Envoy
+		// resources are not leaked.
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			logfields.K8sNamespace:          cecObjectMeta.GetNamespace(),
+			logfields.CiliumEnvoyConfigName: cecObjectMeta.GetName(),
+		}).Warning("Failed to parse resources of deleted config, falling back to best-effort deletion by resource name")
+		resources = resourceNamesFromSpec(cecObjectMeta.GetNamespace(), cecObjectMeta.GetName(), cecSpec.Resources)
+	}
+
+	return r.deleteResources(cecObjectMeta, cecSpec, resources)
+}
+
+// deleteCiliumEnvoyConfigResources deletes a previously applied config using
+// the resource names stored at apply time, avoiding a re-parse of the spec at
+// delete time. When no stored resources are available (e.g. the config was
+// applied before the agent restarted), it falls back to re-parsing the spec
+// via deleteCiliumEnvoyConfig.
+func (r *cecManager) deleteCiliumEnvoyConfigResources(cecObjectMeta metav1.ObjectMeta, cecSpec *ciliumv2.CiliumEnvoyConfigSpec, resources *envoy.Resources) error {
+	if resources == nil {
+		return r.deleteCiliumEnvoyConfig(cecObjectMeta, cecSpec)
 	}
+	return r.deleteResources(cecObjectMeta, cecSpec, *resources)
+}
 
+func (r *cecManager) deleteResources(cecObjectMeta metav1.ObjectMeta, cecSpec *ciliumv2.CiliumEnvoyConfigSpec, resources envoy.Resources) error {
 	name := service.L7LBResourceName{Name: cecObjectMeta.Name, Namespace: cecObjectMeta.Namespace}
 	if err := r.deleteK8sServiceRedirects(name, cecSpec); err != nil {
 		return fmt.Errorf("failed to delete k8s service redirects: %w", err)
@@ -474,9 +651,9 @@ func (r *cecManager) deleteK8sServiceRedirects(resourceName service.L7LBResource
 	return nil
 }
 
-func (r *cecManager) registerServiceSync(serviceName loadbalancer.ServiceName, resourceName service.L7LBResourceName, ports []string) error {
+func (r *cecManager) registerServiceSync(serviceName loadbalancer.ServiceName, resourceName service.L7LBResourceName, ports []string, tlsSecret string) error {
 	// Register service usage in Envoy backend sync
-	r.backendSyncer.RegisterServiceUsageInCEC(serviceName, resourceName, ports)
+	r.backendSyncer.RegisterServiceUsageInCEC(serviceName, resourceName, ports, tlsSecret)
 
 	// Register Envoy Backend Sync for the specific service in the service manager.
 	// A re-registration will trigger an implicit re-synchronization.
@@ -509,6 +686,46 @@ func (r *cecManager) deregisterServiceSync(serviceName loadbalancer.ServiceName,
 	return nil
 }
 
+// injectUpstreamTLS sets an upstream TLS transport socket on the clusters
+// generated for backend services that reference a TLS secret. The secret is
+// distributed to Envoy via SDS, like secrets referenced by transport sockets
+// inlined in the CEC resources. Clusters that already configure a transport
+// socket are left untouched.
+func (r *cecManager) injectUpstreamTLS(cecNamespace, cecName string, spec *ciliumv2.CiliumEnvoyConfigSpec, resources envoy.Resources) {
+	for _, svc := range spec.BackendServices {
+		if svc.TLSSecret == "" {
+			continue
+		}
+		resourceName := service.L7LBResourceName{Name: cecName, Namespace: cecNamespace}
+		serviceName := getServiceName(resourceName, svc.Name, svc.Namespace, false)
+		secretName, _ := api.ResourceQualifiedName(cecNamespace, cecName, svc.TLSSecret)
+
+		for _, cluster := range resources.Clusters {
+			if cluster.TransportSocket != nil {
+				continue
+			}
+			if cluster.Name != serviceName.String() && !strings.HasPrefix(cluster.Name, serviceName.String()+":") {
+				continue
+			}
+			cluster.TransportSocket = &envoy_config_core.TransportSocket{
+				Name: "envoy.transport_sockets.tls",
+				ConfigType: &envoy_config_core.TransportSocket_TypedConfig{
+					TypedConfig: toAny(&envoy_config_tls.UpstreamTlsContext{
+						CommonTlsContext: &envoy_config_tls.CommonTlsContext{
+							TlsCertificateSdsSecretConfigs: []*envoy_config_tls.SdsSecretConfig{
+								{
+									Name:      secretName,
+									SdsConfig: envoy.CiliumXDSConfigSource,
+								},
+							},
+						},
+					}),
+				},
+			}
+		}
+	}
+}
+
 // getServiceName enforces namespacing for service references in Cilium Envoy Configs
 func getServiceName(resourceName service.L7LBResourceName, name, namespace string, isFrontend bool) loadbalancer.ServiceName {
 	if resourceName.Namespace == "" {
@@ -13,17 +13,41 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 
 	cmtypes "github.com/cilium/cilium/pkg/clustermesh/types"
+	"github.com/cilium/cilium/pkg/envoy"
 	"github.com/cilium/cilium/pkg/k8s"
 	cilium_v2 "github.com/cilium/cilium/pkg/k8s/apis/cilium.io/v2"
 	slim_corev1 "github.com/cilium/cilium/pkg/k8s/slim/k8s/api/core/v1"
 	slim_metav1 "github.com/cilium/cilium/pkg/k8s/slim/k8s/apis/meta/v1"
 	"github.com/cilium/cilium/pkg/k8s/slim/k8s/apis/util/intstr"
 	"github.com/cilium/cilium/pkg/loadbalancer"
+	"github.com/cilium/cilium/pkg/service"
+	"github.com/cilium/cilium/pkg/time"
 )
 
+// fakeServiceManager counts the backend sync (de)registrations issued by the
+// manager. All other ServiceManager calls panic via the embedded nil
+// interface.
+type fakeServiceManager struct {
+	service.ServiceManager
+
+	backendSyncRegistrations   int
+	backendSyncDeregistrations int
+}
+
+func (f *fakeServiceManager) RegisterL7LBServiceBackendSync(loadbalancer.ServiceName, service.BackendSyncer) error {
+	f.backendSyncRegistrations++
+	return nil
+}
+
+func (f *fakeServiceManager) DeregisterL7LBServiceBackendSync(loadbalancer.ServiceName, service.BackendSyncer) error {
+	f.backendSyncDeregistrations++
+	return nil
+}
+
 var envoySpec = []byte(`apiVersion: cilium.io/v2
 kind: CiliumClusterwideEnvoyConfig
 metadata:
@@ -517,3 +541,80 @@ func Test_convertToLBService(t *testing.T) {
 		})
 	}
 }
+
+func TestBackendServicePortOnlyChange(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	svcManager := &fakeServiceManager{}
+	m := &cecManager{
+		logger:         logger,
+		serviceManager: svcManager,
+		backendSyncer:  newEnvoyServiceBackendSyncer(logger, nil),
+	}
+
+	resourceName := service.L7LBResourceName{Name: "test-cec", Namespace: "ns"}
+	oldSpec := &cilium_v2.CiliumEnvoyConfigSpec{
+		BackendServices: []*cilium_v2.Service{
+			{Name: "svc", Namespace: "ns", Ports: []string{"8080"}},
+		},
+	}
+	newSpec := &cilium_v2.CiliumEnvoyConfigSpec{
+		BackendServices: []*cilium_v2.Service{
+			{Name: "svc", Namespace: "ns", Ports: []string{"8080", "8443"}},
+		},
+	}
+
+	// Seed the registration as addK8sServiceRedirects would have.
+	serviceName := getServiceName(resourceName, "svc", "ns", false)
+	require.NoError(t, m.registerServiceSync(serviceName, resourceName, oldSpec.BackendServices[0].Ports, ""))
+	require.Equal(t, 1, svcManager.backendSyncRegistrations)
+
+	// A port-only change refreshes the registration in place: the new port
+	// set is registered without a deregistration in between.
+	require.NoError(t, m.removeK8sServiceRedirects(resourceName, oldSpec, newSpec, envoy.Resources{}, envoy.Resources{}))
+	require.Equal(t, 2, svcManager.backendSyncRegistrations)
+	require.Equal(t, 0, svcManager.backendSyncDeregistrations)
+
+	m.backendSyncer.l7lbSvcsMutex.RLock()
+	l7lbInfo := m.backendSyncer.l7lbSvcs[serviceName]
+	m.backendSyncer.l7lbSvcsMutex.RUnlock()
+	require.NotNil(t, l7lbInfo)
+	require.Equal(t, []string{"8080", "8443"}, l7lbInfo.GetAllFrontendPorts())
+
+	// An unchanged backend service is left alone.
+	require.NoError(t, m.removeK8sServiceRedirects(resourceName, newSpec, newSpec, envoy.Resources{}, envoy.Resources{}))
+	require.Equal(t, 2, svcManager.backendSyncRegistrations)
+	require.Equal(t, 0, svcManager.backendSyncDeregistrations)
+
+	// A removed backend service is still deregistered.
+	require.NoError(t, m.removeK8sServiceRedirects(resourceName, newSpec, &cilium_v2.CiliumEnvoyConfigSpec{}, envoy.Resources{}, envoy.Resources{}))
+	require.Equal(t, 1, svcManager.backendSyncDeregistrations)
+}
+
+func TestConfigTimeout(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	manager := &cecManager{
+		logger:             logger,
+		envoyConfigTimeout: 30 * time.Second,
+	}
+
+	// Without the annotation, the global default applies.
+	meta := metav1.ObjectMeta{Name: "cec", Namespace: "default"}
+	require.Equal(t, 30*time.Second, manager.configTimeout(&meta))
+
+	// A valid override is honored.
+	meta.Annotations = map[string]string{envoyConfigTimeoutAnnotation: "2m"}
+	require.Equal(t, 2*time.Minute, manager.configTimeout(&meta))
+
+	// Overrides are capped to the maximum.
+	meta.Annotations[envoyConfigTimeoutAnnotation] = "24h"
+	require.Equal(t, maxEnvoyConfigTimeout, manager.configTimeout(&meta))
+
+	// Unparseable and non-positive values fall back to the default.
+	meta.Annotations[envoyConfigTimeoutAnnotation] = "soon"
+	require.Equal(t, 30*time.Second, manager.configTimeout(&meta))
+	meta.Annotations[envoyConfigTimeoutAnnotation] = "-1s"
+	require.Equal(t, 30*time.Second, manager.configTimeout(&meta))
+}
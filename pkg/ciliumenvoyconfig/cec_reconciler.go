@@ -13,6 +13,7 @@ import (
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/cilium/cilium/pkg/envoy"
 	ciliumv2 "github.com/cilium/cilium/pkg/k8s/apis/cilium.io/v2"
 	"github.com/cilium/cilium/pkg/k8s/resource"
 	"github.com/cilium/cilium/pkg/k8s/slim/k8s/apis/labels"
@@ -51,6 +52,10 @@ type config struct {
 	// This is only used when checking whether an existing config selected
 	// the local node. (instead of re-evaluating using the node selector)
 	selectsLocalNode bool
+	// resources are the parsed resource names captured when the config was
+	// applied, so that deletion does not depend on the spec still parsing.
+	// Nil when the config has not been applied on the local node.
+	resources *envoy.Resources
 }
 
 func newCiliumEnvoyConfigReconciler(params reconcilerParams) *ciliumEnvoyConfigReconciler {
@@ -184,10 +189,22 @@ func (r *ciliumEnvoyConfigReconciler) reconcileExistingConfigsLocked(ctx context
 	// configs should be reconciled.
 	var reconcileErr error
 
+	// Configs that newly select the local node are collected and applied in a
+	// single batched xDS update at the end, so that a node label change
+	// affecting many configs doesn't trigger one Envoy push per config.
+	batch := map[resource.Key]*config{}
+	previous := map[resource.Key]*config{}
+
 	for key, cfg := range r.configs {
 		scopedLogger := r.logger.WithField("key", key)
 
-		err := r.configUpsertedInternal(ctx, key, cfg, false /* spec didn't change */)
+		key := key
+		cfg := cfg
+		err := r.configUpsertedWithAdd(ctx, key, cfg, false /* spec didn't change */, func(metav1.ObjectMeta, *ciliumv2.CiliumEnvoyConfigSpec) error {
+			batch[key] = cfg
+			previous[key] = r.configs[key]
+			return nil
+		})
 		if err != nil {
 			scopedLogger.WithError(err).Error("failed to reconcile existing configs")
 			// don't prevent reconciliation of other configs in case of an error for a particular config
@@ -196,6 +213,16 @@ func (r *ciliumEnvoyConfigReconciler) reconcileExistingConfigsLocked(ctx context
 		}
 	}
 
+	if len(batch) > 0 {
+		for key, err := range r.manager.addCiliumEnvoyConfigs(batch) {
+			r.logger.WithField("key", key).WithError(err).Error("failed to reconcile existing configs")
+			// Restore the previous bookkeeping entry so that the failed
+			// config is picked up again by the next reconciliation.
+			r.configs[key] = previous[key]
+			reconcileErr = errors.Join(reconcileErr, fmt.Errorf("failed to reconcile existing config (%s): %w", key, err))
+		}
+	}
+
 	return reconcileErr
 }
 
@@ -207,6 +234,13 @@ func (r *ciliumEnvoyConfigReconciler) configUpserted(ctx context.Context, key re
 }
 
 func (r *ciliumEnvoyConfigReconciler) configUpsertedInternal(ctx context.Context, key resource.Key, cfg *config, specMayChanged bool) error {
+	return r.configUpsertedWithAdd(ctx, key, cfg, specMayChanged, r.manager.addCiliumEnvoyConfig)
+}
+
+// configUpsertedWithAdd is configUpsertedInternal with the action for newly
+// applicable configs factored out, so that callers reconciling many configs at
+// once can defer the adds into a batched xDS update.
+func (r *ciliumEnvoyConfigReconciler) configUpsertedWithAdd(ctx context.Context, key resource.Key, cfg *config, specMayChanged bool, addFn func(metav1.ObjectMeta, *ciliumv2.CiliumEnvoyConfigSpec) error) error {
 	scopedLogger := r.logger.WithField("key", key)
 
 	selectsLocalNode, err := r.configSelectsLocalNode(cfg)
@@ -222,13 +256,13 @@ func (r *ciliumEnvoyConfigReconciler) configUpsertedInternal(ctx context.Context
 
 	case !isApplied && selectsLocalNode:
 		scopedLogger.Debug("New config selects the local node - adding config")
-		if err := r.manager.addCiliumEnvoyConfig(cfg.meta, cfg.spec); err != nil {
+		if err := addFn(cfg.meta, cfg.spec); err != nil {
 			return err
 		}
 
 	case isApplied && selectsLocalNode && !appliedConfig.selectsLocalNode:
 		scopedLogger.Debug("Config now selects the local Node - adding previously filtered config")
-		if err := r.manager.addCiliumEnvoyConfig(cfg.meta, cfg.spec); err != nil {
+		if err := addFn(cfg.meta, cfg.spec); err != nil {
 			return err
 		}
 
@@ -243,12 +277,20 @@ func (r *ciliumEnvoyConfigReconciler) configUpsertedInternal(ctx context.Context
 
 	case isApplied && !selectsLocalNode && appliedConfig.selectsLocalNode:
 		scopedLogger.Debug("Config no longer selects the local Node - deleting previously applied config")
-		if err := r.manager.deleteCiliumEnvoyConfig(appliedConfig.meta, appliedConfig.spec); err != nil {
+		if err := r.manager.deleteCiliumEnvoyConfigResources(appliedConfig.meta, appliedConfig.spec, appliedConfig.resources); err != nil {
 			return err
 		}
 	}
 
-	r.configs[key] = &config{meta: cfg.meta, spec: cfg.spec, selectsLocalNode: selectsLocalNode}
+	entry := &config{meta: cfg.meta, spec: cfg.spec, selectsLocalNode: selectsLocalNode}
+	if selectsLocalNode {
+		// Remember the parsed resource names so that the config can be
+		// deleted later even if the spec no longer passes validation.
+		if resources, err := r.manager.parseConfigResources(cfg.meta, cfg.spec); err == nil {
+			entry.resources = &resources
+		}
+	}
+	r.configs[key] = entry
 
 	return nil
 }
@@ -271,7 +313,7 @@ func (r *ciliumEnvoyConfigReconciler) configDeleted(ctx context.Context, key res
 
 	case isApplied && appliedConfig.selectsLocalNode:
 		scopedLogger.Debug("Deleting applied CEC")
-		if err := r.manager.deleteCiliumEnvoyConfig(appliedConfig.meta, appliedConfig.spec); err != nil {
+		if err := r.manager.deleteCiliumEnvoyConfigResources(appliedConfig.meta, appliedConfig.spec, appliedConfig.resources); err != nil {
 			return err
 		}
 	}
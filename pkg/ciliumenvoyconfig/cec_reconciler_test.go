@@ -17,6 +17,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sRuntime "k8s.io/apimachinery/pkg/runtime"
 
+	"github.com/cilium/cilium/pkg/envoy"
 	ciliumv2 "github.com/cilium/cilium/pkg/k8s/apis/cilium.io/v2"
 	"github.com/cilium/cilium/pkg/k8s/resource"
 	slim_metav1 "github.com/cilium/cilium/pkg/k8s/slim/k8s/apis/meta/v1"
@@ -765,6 +766,24 @@ func (r *fakeCECManager) addCiliumEnvoyConfig(cecObjectMeta metav1.ObjectMeta, c
 	return nil
 }
 
+func (r *fakeCECManager) addCiliumEnvoyConfigs(configs map[resource.Key]*config) map[resource.Key]error {
+	errs := map[resource.Key]error{}
+	for key, cfg := range configs {
+		if err := r.addCiliumEnvoyConfig(cfg.meta, cfg.spec); err != nil {
+			errs[key] = err
+		}
+	}
+	return errs
+}
+
+func (r *fakeCECManager) deleteCiliumEnvoyConfigResources(cecObjectMeta metav1.ObjectMeta, cecSpec *ciliumv2.CiliumEnvoyConfigSpec, resources *envoy.Resources) error {
+	return r.deleteCiliumEnvoyConfig(cecObjectMeta, cecSpec)
+}
+
+func (r *fakeCECManager) parseConfigResources(cecObjectMeta metav1.ObjectMeta, cecSpec *ciliumv2.CiliumEnvoyConfigSpec) (envoy.Resources, error) {
+	return envoy.Resources{}, nil
+}
+
 func (r *fakeCECManager) deleteCiliumEnvoyConfig(cecObjectMeta metav1.ObjectMeta, cecSpec *ciliumv2.CiliumEnvoyConfigSpec) error {
 	namespacedName := fmt.Sprintf("%s/%s", cecObjectMeta.Namespace, cecObjectMeta.Name)
 
@@ -561,6 +561,51 @@ func qualifyTcpProxyResourceNames(namespace, name string, tcpProxy *envoy_config
 	return updated
 }
 
+// resourceNamesFromSpec extracts the qualified names of the resources in the
+// given spec without full parsing or validation, so that a config whose spec
+// no longer validates can still be deleted best-effort. Resources which
+// cannot be unmarshaled or carry no name are skipped.
+func resourceNamesFromSpec(cecNamespace string, cecName string, xdsResources []cilium_v2.XDSResource) envoy.Resources {
+	resources := envoy.Resources{}
+	for _, res := range xdsResources {
+		if res.TypeUrl == "" {
+			continue
+		}
+		message, err := res.UnmarshalNew()
+		if err != nil {
+			continue
+		}
+		switch res.GetTypeUrl() {
+		case envoy.ListenerTypeURL:
+			if listener, ok := message.(*envoy_config_listener.Listener); ok && listener.Name != "" {
+				qualifiedName, _ := api.ResourceQualifiedName(cecNamespace, cecName, listener.Name, api.ForceNamespace)
+				resources.Listeners = append(resources.Listeners, &envoy_config_listener.Listener{Name: qualifiedName})
+			}
+		case envoy.RouteTypeURL:
+			if route, ok := message.(*envoy_config_route.RouteConfiguration); ok && route.Name != "" {
+				qualifiedName, _ := api.ResourceQualifiedName(cecNamespace, cecName, route.Name, api.ForceNamespace)
+				resources.Routes = append(resources.Routes, &envoy_config_route.RouteConfiguration{Name: qualifiedName})
+			}
+		case envoy.ClusterTypeURL:
+			if cluster, ok := message.(*envoy_config_cluster.Cluster); ok && cluster.Name != "" {
+				qualifiedName, _ := api.ResourceQualifiedName(cecNamespace, cecName, cluster.Name)
+				resources.Clusters = append(resources.Clusters, &envoy_config_cluster.Cluster{Name: qualifiedName})
+			}
+		case envoy.EndpointTypeURL:
+			if endpoints, ok := message.(*envoy_config_endpoint.ClusterLoadAssignment); ok && endpoints.ClusterName != "" {
+				qualifiedName, _ := api.ResourceQualifiedName(cecNamespace, cecName, endpoints.ClusterName)
+				resources.Endpoints = append(resources.Endpoints, &envoy_config_endpoint.ClusterLoadAssignment{ClusterName: qualifiedName})
+			}
+		case envoy.SecretTypeURL:
+			if secret, ok := message.(*envoy_config_tls.Secret); ok && secret.Name != "" {
+				qualifiedName, _ := api.ResourceQualifiedName(cecNamespace, cecName, secret.Name)
+				resources.Secrets = append(resources.Secrets, &envoy_config_tls.Secret{Name: qualifiedName})
+			}
+		}
+	}
+	return resources
+}
+
 func qualifyRouteConfigurationResourceNames(namespace, name string, routeConfig *envoy_config_route.RouteConfiguration) (updated bool) {
 	// Strictly not a reference, and may be an empty string
 	routeConfig.Name, updated = api.ResourceQualifiedName(namespace, name, routeConfig.Name, api.ForceNamespace)
@@ -40,13 +40,15 @@ var Cell = cell.Module(
 )
 
 type cecConfig struct {
-	EnvoyConfigRetryInterval time.Duration
-	EnvoyConfigTimeout       time.Duration
+	EnvoyConfigRetryInterval         time.Duration
+	EnvoyConfigTimeout               time.Duration
+	EnvoyConfigServiceMissingWarning bool
 }
 
 func (r cecConfig) Flags(flags *pflag.FlagSet) {
 	flags.Duration("envoy-config-retry-interval", 15*time.Second, "Interval in which an attempt is made to reconcile failed EnvoyConfigs. If the duration is zero, the retry is deactivated.")
 	flags.Duration("envoy-config-timeout", 2*time.Minute, "Timeout that determines how long to wait for Envoy to N/ACK CiliumEnvoyConfig resources")
+	flags.Bool("envoy-config-service-missing-warning", true, "Warn if a service referenced by a CiliumEnvoyConfig does not exist. The redirect is registered regardless, so it activates once the service appears.")
 }
 
 type reconcilerParams struct {
@@ -142,5 +144,6 @@ type managerParams struct {
 
 func newCECManager(params managerParams) ciliumEnvoyConfigManager {
 	return newCiliumEnvoyConfigManager(params.Logger, params.PolicyUpdater, params.ServiceManager, params.XdsServer,
-		params.BackendSyncer, params.ResourceParser, params.Config.EnvoyConfigTimeout, params.Services, params.Endpoints)
+		params.BackendSyncer, params.ResourceParser, params.Config.EnvoyConfigTimeout, params.Config.EnvoyConfigServiceMissingWarning,
+		params.Services, params.Endpoints)
 }
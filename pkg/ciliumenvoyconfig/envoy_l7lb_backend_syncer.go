@@ -72,7 +72,7 @@ func (r *envoyServiceBackendSyncer) Sync(svc *loadbalancer.SVC) error {
 	return nil
 }
 
-func (r *envoyServiceBackendSyncer) RegisterServiceUsageInCEC(svcName loadbalancer.ServiceName, resourceName service.L7LBResourceName, frontendPorts []string) {
+func (r *envoyServiceBackendSyncer) RegisterServiceUsageInCEC(svcName loadbalancer.ServiceName, resourceName service.L7LBResourceName, frontendPorts []string, tlsSecret string) {
 	r.l7lbSvcsMutex.Lock()
 	defer r.l7lbSvcsMutex.Unlock()
 
@@ -88,11 +88,16 @@ func (r *envoyServiceBackendSyncer) RegisterServiceUsageInCEC(svcName loadbalanc
 
 	l7lbInfo.backendRefs[resourceName] = backendSyncCECInfo{
 		frontendPorts: frontendPorts,
+		tlsSecret:     tlsSecret,
 	}
 
 	r.l7lbSvcs[svcName] = l7lbInfo
 }
 
+// DeregisterServiceUsageInCEC removes the given CEC's reference to the
+// service. Any TLS secret reference recorded for the CEC is dropped with it,
+// so once the last referencing CEC is removed the service entry, including
+// its secret references, is cleaned up entirely.
 func (r *envoyServiceBackendSyncer) DeregisterServiceUsageInCEC(svcName loadbalancer.ServiceName, resourceName service.L7LBResourceName) bool {
 	r.l7lbSvcsMutex.Lock()
 	defer r.l7lbSvcsMutex.Unlock()
@@ -253,4 +258,9 @@ type backendSyncCECInfo struct {
 	//
 	// If nil, all the available backends will be used.
 	frontendPorts []string
+
+	// Name of a TLS secret, optionally prefixed with a namespace followed
+	// by a '/', used for upstream TLS towards the service's backends.
+	// Empty if the CEC does not reference a secret for this service.
+	tlsSecret string
 }
@@ -4,45 +4,42 @@
 package probes
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net"
 	"sync"
 
 	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
 
 	"github.com/cilium/cilium/pkg/netns"
 )
 
-var (
-	managedNeighborOnce   sync.Once
-	managedNeighborResult error
-)
-
-// HaveManagedNeighbors returns nil if the host supports managed neighbor entries (NTF_EXT_MANAGED).
-// On unexpected probe results this function will terminate with log.Fatal().
-func HaveManagedNeighbors() error {
-	managedNeighborOnce.Do(func() {
-		ns, err := netns.New()
-		if err != nil {
-			managedNeighborResult = fmt.Errorf("create netns: %w", err)
-			return
-		}
-		defer ns.Close()
-
-		// In order to call haveManagedNeighbors safely, it has to be started
-		// in a standalone netns
-		managedNeighborResult = ns.Do(func() error {
-			return haveManagedNeighbors()
-		})
-
-		// if we encounter a different error than ErrNotSupported, terminate the agent.
-		if managedNeighborResult != nil && !errors.Is(managedNeighborResult, ErrNotSupported) {
-			log.WithError(managedNeighborResult).Fatal("failed to probe managed neighbor support")
-		}
+// HaveManagedNeighbors returns nil if the host supports managed neighbor
+// entries (NTF_EXT_MANAGED) and ErrNotSupported if it does not. Errors
+// wrapping ErrProbeSetup indicate that the probe environment (netns, veth
+// pair) could not be set up, in which case no statement about kernel support
+// can be made and callers should surface the error instead of degrading.
+var HaveManagedNeighbors = sync.OnceValue(func() error {
+	ns, err := netns.New()
+	if err != nil {
+		return fmt.Errorf("%w: create netns: %w", ErrProbeSetup, err)
+	}
+	defer ns.Close()
+
+	return haveManagedNeighborsInNS(ns)
+})
+
+// haveManagedNeighborsInNS runs the managed neighbor probe in the given
+// namespace. Tests can pass a prepared namespace instead of letting
+// HaveManagedNeighbors create a fresh one.
+func haveManagedNeighborsInNS(ns *netns.NetNS) error {
+	// In order to call haveManagedNeighbors safely, it has to be started
+	// in a standalone netns
+	return ns.Do(func() error {
+		return haveManagedNeighbors()
 	})
-
-	return managedNeighborResult
 }
 
 func haveManagedNeighbors() (outer error) {
@@ -56,7 +53,7 @@ func haveManagedNeighbors() (outer error) {
 	}
 
 	if err := netlink.LinkAdd(veth); err != nil {
-		return fmt.Errorf("failed to add dummy veth: %w", err)
+		return fmt.Errorf("%w: failed to add dummy veth: %w", ErrProbeSetup, err)
 	}
 
 	neigh := netlink.Neigh{
@@ -67,12 +64,17 @@ func haveManagedNeighbors() (outer error) {
 	}
 
 	if err := netlink.NeighAdd(&neigh); err != nil {
-		return fmt.Errorf("failed to add neighbor: %w", err)
+		// Kernels without support for the extended flags reject the
+		// request with EINVAL.
+		if errors.Is(err, unix.EINVAL) {
+			return fmt.Errorf("failed to add neighbor: %w", ErrNotSupported)
+		}
+		return fmt.Errorf("%w: failed to add neighbor: %w", ErrProbeSetup, err)
 	}
 
 	nl, err := netlink.NeighList(veth.Index, 0)
 	if err != nil {
-		return fmt.Errorf("failed to list neighbors: %w", err)
+		return fmt.Errorf("%w: failed to list neighbors: %w", ErrProbeSetup, err)
 	}
 
 	for _, n := range nl {
@@ -91,3 +93,105 @@ func haveManagedNeighbors() (outer error) {
 
 	return ErrNotSupported
 }
+
+// HaveManagedNeighborUpdates returns nil if the host not only accepts managed
+// neighbor entries (NTF_EXT_MANAGED), but also correctly handles updates and
+// deletions of such entries. Some kernels accept the initial add but mishandle
+// later modifications, which surfaces as stale neighbor entries. Returns
+// ErrNotSupported if any of the steps misbehaves.
+var HaveManagedNeighborUpdates = sync.OnceValue(func() error {
+	ns, err := netns.New()
+	if err != nil {
+		return fmt.Errorf("%w: create netns: %w", ErrProbeSetup, err)
+	}
+	defer ns.Close()
+
+	return haveManagedNeighborUpdatesInNS(ns)
+})
+
+// haveManagedNeighborUpdatesInNS runs the managed neighbor update probe in the
+// given namespace, see haveManagedNeighborsInNS.
+func haveManagedNeighborUpdatesInNS(ns *netns.NetNS) error {
+	// In order to call haveManagedNeighborUpdates safely, it has to be
+	// started in a standalone netns.
+	return ns.Do(func() error {
+		return haveManagedNeighborUpdates()
+	})
+}
+
+func haveManagedNeighborUpdates() error {
+	// See haveManagedNeighbors for why a veth pair is used here.
+	veth := &netlink.Veth{
+		LinkAttrs: netlink.LinkAttrs{Name: "veth0"},
+		PeerName:  "veth1",
+	}
+
+	if err := netlink.LinkAdd(veth); err != nil {
+		return fmt.Errorf("%w: failed to add dummy veth: %w", ErrProbeSetup, err)
+	}
+
+	neigh := netlink.Neigh{
+		LinkIndex:    veth.Index,
+		IP:           net.IPv4(0, 0, 0, 1),
+		Flags:        NTF_EXT_LEARNED,
+		FlagsExt:     NTF_EXT_MANAGED,
+		HardwareAddr: net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x01},
+	}
+
+	if err := netlink.NeighAdd(&neigh); err != nil {
+		return fmt.Errorf("failed to add neighbor: %w", err)
+	}
+
+	if _, err := findManagedNeighbor(veth.Index, neigh.IP); err != nil {
+		return err
+	}
+
+	// Update the entry's MAC address and verify the managed flags survive.
+	neigh.HardwareAddr = net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x02}
+	if err := netlink.NeighSet(&neigh); err != nil {
+		return fmt.Errorf("failed to update neighbor: %w", err)
+	}
+
+	updated, err := findManagedNeighbor(veth.Index, neigh.IP)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(updated.HardwareAddr, neigh.HardwareAddr) {
+		return ErrNotSupported
+	}
+
+	if err := netlink.NeighDel(&neigh); err != nil {
+		return fmt.Errorf("failed to delete neighbor: %w", err)
+	}
+
+	// The entry must be gone after the delete.
+	nl, err := netlink.NeighList(veth.Index, 0)
+	if err != nil {
+		return fmt.Errorf("failed to list neighbors: %w", err)
+	}
+	for _, n := range nl {
+		if n.IP.Equal(neigh.IP) {
+			return ErrNotSupported
+		}
+	}
+
+	return nil
+}
+
+// findManagedNeighbor returns the neighbor entry for the given IP on the given
+// link, provided it still carries the managed neighbor flags. Returns
+// ErrNotSupported if no such entry exists.
+func findManagedNeighbor(linkIndex int, ip net.IP) (*netlink.Neigh, error) {
+	nl, err := netlink.NeighList(linkIndex, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list neighbors: %w", err)
+	}
+
+	for i, n := range nl {
+		if n.IP.Equal(ip) && n.Flags == NTF_EXT_LEARNED && n.FlagsExt == NTF_EXT_MANAGED {
+			return &nl[i], nil
+		}
+	}
+
+	return nil, ErrNotSupported
+}
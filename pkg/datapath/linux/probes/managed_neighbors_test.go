@@ -6,6 +6,7 @@ package probes
 import (
 	"testing"
 
+	"github.com/cilium/cilium/pkg/netns"
 	"github.com/cilium/cilium/pkg/testutils"
 )
 
@@ -17,3 +18,28 @@ func TestManagedNeighbors(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestManagedNeighborsInjectedNetNS(t *testing.T) {
+	testutils.PrivilegedTest(t)
+	testutils.SkipOnOldKernel(t, "5.16", "NTF_EXT_MANAGED")
+
+	ns, err := netns.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ns.Close()
+
+	// The probe can be driven against a caller-provided namespace.
+	if err := haveManagedNeighborsInNS(ns); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestManagedNeighborUpdates(t *testing.T) {
+	testutils.PrivilegedTest(t)
+	testutils.SkipOnOldKernel(t, "5.16", "NTF_EXT_MANAGED")
+
+	if err := HaveManagedNeighborUpdates(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -67,6 +67,11 @@ func init() {
 // ErrNotSupported indicates that a feature is not supported by the current kernel.
 var ErrNotSupported = errors.New("not supported")
 
+// ErrProbeSetup indicates that a probe's environment could not be set up, as
+// opposed to the probed feature being unsupported by the current kernel.
+// Callers must not interpret it as the feature being absent.
+var ErrProbeSetup = errors.New("probe setup failed")
+
 // KernelParam is a type based on string which represents CONFIG_* kernel
 // parameters which usually have values "y", "n" or "m".
 type KernelParam string
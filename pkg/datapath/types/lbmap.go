@@ -16,13 +16,15 @@ type LBMap interface {
 	UpsertService(*UpsertServiceParams) error
 	UpsertMaglevLookupTable(uint16, map[string]*loadbalancer.Backend, bool) error
 	IsMaglevLookupTableRecreated(bool) bool
+	MaglevRecreatedStatus() (v4, v6 bool)
+	AcknowledgeMaglevRecreated(bool)
 	DeleteService(loadbalancer.L3n4AddrID, int, bool, loadbalancer.SVCNatPolicy) error
 	AddBackend(*loadbalancer.Backend, bool) error
 	UpdateBackendWithState(*loadbalancer.Backend) error
 	DeleteBackendByID(loadbalancer.BackendID) error
 	AddAffinityMatch(uint16, loadbalancer.BackendID) error
 	DeleteAffinityMatch(uint16, loadbalancer.BackendID) error
-	UpdateSourceRanges(uint16, []*cidr.CIDR, []*cidr.CIDR, bool) error
+	UpdateSourceRanges(uint16, []*cidr.CIDR, []*cidr.CIDR, bool) (added, removed []*cidr.CIDR, err error)
 	DumpServiceMaps() ([]*loadbalancer.SVC, []error)
 	DumpBackendMaps() ([]*loadbalancer.Backend, error)
 	DumpAffinityMatches() (BackendIDByServiceIDSet, error)
@@ -54,6 +56,14 @@ type UpsertServiceParams struct {
 	L7LBProxyPort             uint16                   // Non-zero for L7 LB services
 	Name                      loadbalancer.ServiceName // Fully qualified name of the service
 	LoopbackHostport          bool
+	// QuarantinedBackends is the number of backends in quarantined state,
+	// included in NonActiveBackends. Only used for metrics.
+	QuarantinedBackends int
+	// SkipRevNatForSurrogate skips the creation of the reverse NAT entry if
+	// the frontend is a surrogate (wildcard) entry. Reverse translation for
+	// such services is handled by the per-address frontend entries, so the
+	// surrogate's revNAT entry only adds RevNatMap pressure.
+	SkipRevNatForSurrogate bool
 }
 
 // GetOrderedBackends returns an ordered list of backends with all the sorted
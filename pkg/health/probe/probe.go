@@ -4,6 +4,7 @@
 package probe
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -40,3 +41,40 @@ func GetHello(host string) error {
 
 	return nil
 }
+
+// GetHelloWithPayload performs a GET request on the /hello endpoint carrying a
+// request body of payloadBytes zero bytes. Larger payloads exercise the MTU
+// and path MSS towards the probed node, which a minimal request does not. A
+// payloadBytes of 0 behaves like GetHello.
+func GetHelloWithPayload(host string, payloadBytes int) error {
+	if payloadBytes <= 0 {
+		return GetHello(host)
+	}
+
+	hostURL, err := url.Parse(host)
+	if err != nil {
+		return err
+	}
+
+	requestURL, err := hostURL.Parse("/hello")
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, requestURL.String(), bytes.NewReader(make([]byte, payloadBytes)))
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d %s", resp.StatusCode, resp.Status)
+	}
+
+	return nil
+}
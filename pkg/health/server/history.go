@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	healthClientPkg "github.com/cilium/cilium/pkg/health/client"
+	"github.com/cilium/cilium/pkg/lock"
+)
+
+// DefaultProbeHistoryDepth is the number of recent probe results kept per
+// node when Config.ProbeHistoryDepth is left at zero.
+const DefaultProbeHistoryDepth = 30
+
+// successRatesPath is the URL path under which the per-node probe success
+// rates are served, next to the generated REST API.
+const successRatesPath = "/v1beta/probe-success-rates"
+
+// probeHistory keeps a bounded ring buffer of recent probe outcomes per node,
+// so operators can distinguish a flapping node from one that is steadily
+// failing. Memory use is bounded by the node count times the buffer depth,
+// and a node's buffer is dropped when the node is removed from the prober.
+type probeHistory struct {
+	mu    lock.RWMutex
+	depth int
+	nodes map[string]*nodeHistory
+}
+
+// nodeHistory is the ring buffer of one node's most recent probe outcomes.
+type nodeHistory struct {
+	results []bool
+	next    int
+	filled  int
+}
+
+func newProbeHistory(depth int) *probeHistory {
+	if depth <= 0 {
+		depth = DefaultProbeHistoryDepth
+	}
+	return &probeHistory{
+		depth: depth,
+		nodes: make(map[string]*nodeHistory),
+	}
+}
+
+// record appends the outcome of one probe cycle towards the given node,
+// evicting the oldest outcome once the buffer is full.
+func (h *probeHistory) record(nodeName string, success bool) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n, ok := h.nodes[nodeName]
+	if !ok {
+		n = &nodeHistory{results: make([]bool, h.depth)}
+		h.nodes[nodeName] = n
+	}
+	n.results[n.next] = success
+	n.next = (n.next + 1) % len(n.results)
+	if n.filled < len(n.results) {
+		n.filled++
+	}
+}
+
+// forget drops the buffer of the given node, e.g. when the node is removed
+// from the cluster.
+func (h *probeHistory) forget(nodeName string) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.nodes, nodeName)
+}
+
+// successRate returns the fraction of successful probes among the recorded
+// outcomes for the given node and the number of outcomes it is based on.
+func (h *probeHistory) successRate(nodeName string) (rate float64, samples int) {
+	if h == nil {
+		return 0, 0
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	n, ok := h.nodes[nodeName]
+	if !ok || n.filled == 0 {
+		return 0, 0
+	}
+
+	succeeded := 0
+	for i := 0; i < n.filled; i++ {
+		if n.results[i] {
+			succeeded++
+		}
+	}
+	return float64(succeeded) / float64(n.filled), n.filled
+}
+
+// nodeSuccessRate is one entry of the success rate export.
+type nodeSuccessRate struct {
+	NodeName    string  `json:"node_name"`
+	Samples     int     `json:"samples"`
+	Depth       int     `json:"depth"`
+	SuccessRate float64 `json:"success_rate"`
+}
+
+// DumpSuccessRates returns the per-node probe success rate over the recorded
+// history, sorted by node name.
+func (s *Server) DumpSuccessRates() []nodeSuccessRate {
+	rates := []nodeSuccessRate{}
+	if s.history == nil {
+		return rates
+	}
+
+	s.history.mu.RLock()
+	names := make([]string, 0, len(s.history.nodes))
+	for name := range s.history.nodes {
+		names = append(names, name)
+	}
+	depth := s.history.depth
+	s.history.mu.RUnlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		rate, samples := s.history.successRate(name)
+		if samples == 0 {
+			continue
+		}
+		rates = append(rates, nodeSuccessRate{
+			NodeName:    name,
+			Samples:     samples,
+			Depth:       depth,
+			SuccessRate: rate,
+		})
+	}
+	return rates
+}
+
+// recordProbeOutcomes feeds the outcomes of one probe cycle into the history.
+// A probe counts as successful when the node's host addresses all report
+// reachable; nodes which have not been probed yet are skipped.
+func (s *Server) recordProbeOutcomes(report *healthReport) {
+	for _, n := range report.nodes {
+		if n == nil || n.Host == nil || n.Host.PrimaryAddress == nil {
+			continue
+		}
+		switch healthClientPkg.SummarizePathConnectivityStatusType(healthClientPkg.GetAllHostAddresses(n)) {
+		case healthClientPkg.ConnStatusReachable:
+			s.history.record(n.Name, true)
+		case healthClientPkg.ConnStatusUnknown:
+			// Not probed yet, leave the history untouched.
+		default:
+			s.history.record(n.Name, false)
+		}
+	}
+}
+
+// successRatesHandler serves the success rate export on successRatesPath and
+// hands all other requests to the next handler.
+func (s *Server) successRatesHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != successRatesPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(s.DumpSuccessRates()); err != nil {
+			log.WithError(err).Warn("Failed to encode probe success rate export")
+		}
+	})
+}
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeHistory(t *testing.T) {
+	h := newProbeHistory(4)
+
+	// Unknown nodes have no samples.
+	rate, samples := h.successRate("node1")
+	require.Zero(t, rate)
+	require.Zero(t, samples)
+
+	h.record("node1", true)
+	h.record("node1", true)
+	h.record("node1", false)
+
+	rate, samples = h.successRate("node1")
+	require.Equal(t, 3, samples)
+	require.InDelta(t, 2.0/3.0, rate, 1e-9)
+
+	// Overflowing the depth evicts the oldest outcomes.
+	h.record("node1", false)
+	h.record("node1", false)
+	h.record("node1", false)
+
+	rate, samples = h.successRate("node1")
+	require.Equal(t, 4, samples)
+	require.Zero(t, rate)
+
+	// Forgetting a node drops its buffer.
+	h.forget("node1")
+	_, samples = h.successRate("node1")
+	require.Zero(t, samples)
+
+	// A nil history, e.g. on a test-constructed server, is inert.
+	var nilHistory *probeHistory
+	nilHistory.record("node1", true)
+	nilHistory.forget("node1")
+	_, samples = nilHistory.successRate("node1")
+	require.Zero(t, samples)
+}
+
+func TestSuccessRatesHandler(t *testing.T) {
+	s := &Server{history: newProbeHistory(0)}
+	s.history.record("node2", true)
+	s.history.record("node1", true)
+	s.history.record("node1", false)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler := s.successRatesHandler(next)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, successRatesPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var rates []nodeSuccessRate
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rates))
+	require.Equal(t, []nodeSuccessRate{
+		{NodeName: "node1", Samples: 2, Depth: DefaultProbeHistoryDepth, SuccessRate: 0.5},
+		{NodeName: "node2", Samples: 1, Depth: DefaultProbeHistoryDepth, SuccessRate: 1},
+	}, rates)
+
+	// Other paths fall through to the wrapped handler.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hello", nil))
+	require.Equal(t, http.StatusTeapot, rec.Code)
+}
@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	healthModels "github.com/cilium/cilium/api/v1/health/models"
+)
+
+// latenciesPath is the URL path under which the flat per-node latency export
+// is served, next to the generated REST API.
+const latenciesPath = "/v1beta/latencies"
+
+// nodeLatency is one entry of the latency export: the result of a single
+// probe (HTTP or ICMP) towards a single address of a node.
+type nodeLatency struct {
+	NodeName    string `json:"node_name"`
+	IP          string `json:"ip"`
+	AddressType string `json:"address_type"` // "primary" or "secondary"
+	Peer        string `json:"peer"`         // "node" or "health-endpoint"
+	Probe       string `json:"probe"`        // "http" or "icmp"
+	LatencyNs   int64  `json:"latency_ns"`
+	Status      string `json:"status,omitempty"`
+}
+
+// DumpLatencies returns the per-node probe latencies from the most recent
+// connectivity report as a flat list, suitable for consumption by external
+// dashboards without parsing the full status response.
+func (s *Server) DumpLatencies() []nodeLatency {
+	s.RLock()
+	defer s.RUnlock()
+
+	latencies := []nodeLatency{}
+	if s.connectivity == nil {
+		return latencies
+	}
+
+	for _, n := range s.connectivity.nodes {
+		if n == nil {
+			continue
+		}
+		if n.Host != nil {
+			latencies = appendPathLatencies(latencies, n.Name, "node", "primary", n.Host.PrimaryAddress)
+			for _, addr := range n.Host.SecondaryAddresses {
+				latencies = appendPathLatencies(latencies, n.Name, "node", "secondary", addr)
+			}
+		}
+		if n.HealthEndpoint != nil {
+			latencies = appendPathLatencies(latencies, n.Name, "health-endpoint", "primary", n.HealthEndpoint.PrimaryAddress)
+			for _, addr := range n.HealthEndpoint.SecondaryAddresses {
+				latencies = appendPathLatencies(latencies, n.Name, "health-endpoint", "secondary", addr)
+			}
+		}
+	}
+
+	return latencies
+}
+
+func appendPathLatencies(latencies []nodeLatency, nodeName, peer, addressType string, path *healthModels.PathStatus) []nodeLatency {
+	if path == nil {
+		return latencies
+	}
+	if path.HTTP != nil {
+		latencies = append(latencies, nodeLatency{
+			NodeName:    nodeName,
+			IP:          path.IP,
+			AddressType: addressType,
+			Peer:        peer,
+			Probe:       "http",
+			LatencyNs:   path.HTTP.Latency,
+			Status:      path.HTTP.Status,
+		})
+	}
+	if path.Icmp != nil {
+		latencies = append(latencies, nodeLatency{
+			NodeName:    nodeName,
+			IP:          path.IP,
+			AddressType: addressType,
+			Peer:        peer,
+			Probe:       "icmp",
+			LatencyNs:   path.Icmp.Latency,
+			Status:      path.Icmp.Status,
+		})
+	}
+	return latencies
+}
+
+// latenciesHandler serves the latency export on latenciesPath and hands all
+// other requests to the generated REST API handler.
+func (s *Server) latenciesHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != latenciesPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(s.DumpLatencies()); err != nil {
+			log.WithError(err).Warn("Failed to encode latency export")
+		}
+	})
+}
@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpLatencies(t *testing.T) {
+	s := &Server{connectivity: sampleSingleClusterConnectivity}
+
+	latencies := s.DumpLatencies()
+	require.NotEmpty(t, latencies)
+
+	for _, l := range latencies {
+		require.NotEmpty(t, l.NodeName)
+		require.NotEmpty(t, l.IP)
+		require.Contains(t, []string{"http", "icmp"}, l.Probe)
+		require.Contains(t, []string{"node", "health-endpoint"}, l.Peer)
+		require.Contains(t, []string{"primary", "secondary"}, l.AddressType)
+		require.NotZero(t, l.LatencyNs)
+	}
+}
+
+func TestLatenciesHandler(t *testing.T) {
+	s := &Server{connectivity: sampleSingleClusterConnectivity}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler := s.latenciesHandler(next)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, latenciesPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var latencies []nodeLatency
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &latencies))
+	require.Equal(t, s.DumpLatencies(), latencies)
+
+	// Other paths fall through to the wrapped handler.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hello", nil))
+	require.Equal(t, http.StatusTeapot, rec.Code)
+}
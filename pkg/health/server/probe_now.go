@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package server
+
+import (
+	"net/http"
+)
+
+// probeNowPath is the URL path under which an immediate probe cycle can be
+// triggered, next to the generated REST API.
+const probeNowPath = "/v1beta/probe-now"
+
+// probeNowHandler triggers an immediate probe cycle for requests on
+// probeNowPath and hands all other requests to the next handler. The request
+// returns once the fresh report is available, so a subsequent status query
+// observes the new results; when the probe does not complete within the probe
+// interval, 503 is returned.
+func (s *Server) probeNowHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != probeNowPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Method != http.MethodPut && r.Method != http.MethodPost {
+			http.Error(w, "probe trigger requires PUT or POST", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := s.ProbeNow(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunProbeNow(t *testing.T) {
+	p := newProber(&Server{}, nodeMap{})
+
+	// A trigger is released by the completion of a probe cycle.
+	done := make(chan error, 1)
+	go func() {
+		done <- p.RunProbeNow(time.Second)
+	}()
+	// Wait until the trigger is pending, then run the cycle it requested.
+	require.Eventually(t, func() bool { return len(p.probeNow) == 1 }, time.Second, time.Millisecond)
+	p.runHTTPProbe()
+	require.NoError(t, <-done)
+
+	// Without a probe cycle the trigger times out.
+	require.ErrorContains(t, p.RunProbeNow(time.Millisecond), "timed out")
+}
+
+func TestProbeNowHandler(t *testing.T) {
+	s := &Server{}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler := s.probeNowHandler(next)
+
+	// Triggering requires a method with side-effect semantics.
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, probeNowPath, nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	// Without a running prober the trigger is unavailable.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, probeNowPath, nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	// Other paths fall through to the wrapped handler.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hello", nil))
+	require.Equal(t, http.StatusTeapot, rec.Code)
+}
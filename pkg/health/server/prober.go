@@ -4,6 +4,7 @@
 package server
 
 import (
+	"fmt"
 	"net"
 	"strconv"
 	"strings"
@@ -45,6 +46,16 @@ type prober struct {
 	start   time.Time
 	results map[ipString]*models.PathStatus
 	nodes   nodeMap
+
+	// probeNow signals the RunLoop to run an HTTP probe cycle immediately.
+	// The channel is buffered with capacity one, so concurrent trigger
+	// requests coalesce into a single probe.
+	probeNow chan struct{}
+
+	// probeCycle is closed and replaced at the end of every HTTP probe
+	// cycle. Waiters grab the current channel before triggering and block
+	// until it is closed. Protected by the prober lock.
+	probeCycle chan struct{}
 }
 
 // copyResultRLocked makes a copy of the path status for the specified IP.
@@ -206,12 +217,22 @@ func (p *prober) setNodes(added nodeMap, removed nodeMap) {
 		}
 	}
 
+	addedNames := map[string]struct{}{}
+	for _, n := range added {
+		addedNames[n.Name] = struct{}{}
+	}
+
 	for _, n := range removed {
 		for elem := range n.Addresses() {
 			if _, ok := readdedIPs[elem.IP]; !ok {
 				p.RemoveIP(elem.IP)
 			}
 		}
+		// Drop the probe history of nodes leaving the cluster. Updated nodes
+		// appear in both maps and keep their history.
+		if _, ok := addedNames[n.Name]; !ok && p.server != nil {
+			p.server.history.forget(n.Name)
+		}
 	}
 
 	for _, n := range added {
@@ -277,7 +298,7 @@ func (p *prober) httpProbe(node string, ip string) *models.ConnectivityStatus {
 
 	scopedLog.Debug("Greeting host")
 	start := time.Now()
-	err := probe.GetHello(host)
+	err := probe.GetHelloWithPayload(host, p.server.Config.HTTPProbePayloadBytes)
 	rtt := time.Since(start)
 	if err == nil {
 		if debugLogsEnabled {
@@ -364,6 +385,36 @@ func (p *prober) runHTTPProbe() {
 			p.Unlock()
 		}
 	}
+
+	// Notify anyone waiting for the completion of this probe cycle, see
+	// RunProbeNow.
+	p.Lock()
+	close(p.probeCycle)
+	p.probeCycle = make(chan struct{})
+	p.Unlock()
+}
+
+// RunProbeNow signals the RunLoop to run an HTTP probe cycle immediately and
+// blocks until the cycle has finished or the timeout elapses. Triggers issued
+// while a trigger is already pending coalesce into a single probe; the
+// waiters are all released by the completion of the same cycle.
+func (p *prober) RunProbeNow(timeout time.Duration) error {
+	p.RLock()
+	done := p.probeCycle
+	p.RUnlock()
+
+	select {
+	case p.probeNow <- struct{}{}:
+	default:
+		// A trigger is already pending, wait for its probe cycle.
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("timed out after %s waiting for probe cycle to complete", timeout)
+	}
 }
 
 // Run sends a single probes out to all of the other cilium nodes to gather
@@ -403,6 +454,9 @@ func (p *prober) RunLoop() {
 			case <-tick.C:
 				p.runHTTPProbe()
 				continue
+			case <-p.probeNow:
+				p.runHTTPProbe()
+				continue
 			}
 		}
 		tick.Stop()
@@ -418,6 +472,8 @@ func newProber(s *Server, nodes nodeMap) *prober {
 		server:       s,
 		proberExited: make(chan bool),
 		stop:         make(chan bool),
+		probeNow:     make(chan struct{}, 1),
+		probeCycle:   make(chan struct{}),
 		results:      make(map[ipString]*models.PathStatus),
 		nodes:        make(nodeMap),
 	}
@@ -5,7 +5,9 @@ package server
 
 import (
 	"fmt"
+	"net"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/cilium/cilium/api/v1/client/daemon"
@@ -39,8 +41,37 @@ type Config struct {
 	ProbeDeadline time.Duration
 	HTTPPathPort  int
 	HealthAPISpec *healthApi.Spec
+
+	// NodeSelector, if non-nil, restricts which cluster nodes this instance
+	// probes, e.g. to limit probe traffic to nodes in the same zone in very
+	// large clusters. Nodes for which the function returns false are not
+	// handed to the prober. If the selector matches no nodes, nothing is
+	// probed while the status API remains served.
+	NodeSelector func(*models.NodeElement) bool
+
+	// HTTPProbePayloadBytes is the size of the request body sent with each
+	// HTTP probe, to detect MTU or path-MSS issues which a minimal request
+	// would not surface. The default of 0 keeps the current behavior of a
+	// minimal request. Must not exceed MaxHTTPProbePayloadBytes.
+	HTTPProbePayloadBytes int
+
+	// ProbeFamilies restricts which address families the prober targets,
+	// e.g. []string{"ipv4"} to cut probe volume in dual-stack clusters.
+	// This only affects outgoing probes, not which addresses the listeners
+	// bind to. An empty list probes all families the nodes advertise.
+	ProbeFamilies []string
+
+	// ProbeHistoryDepth is the number of recent probe outcomes kept per node
+	// for the success rate export. Zero selects DefaultProbeHistoryDepth,
+	// negative values are rejected.
+	ProbeHistoryDepth int
 }
 
+// MaxHTTPProbePayloadBytes bounds Config.HTTPProbePayloadBytes. Probes run
+// against every known node on every interval, so payloads beyond a few MTUs
+// only add load without improving the signal.
+const MaxHTTPProbePayloadBytes = 65536
+
 // ipString is an IP address used as a more descriptive type name in maps.
 type ipString string
 
@@ -68,6 +99,20 @@ type Server struct {
 	lock.RWMutex
 	connectivity *healthReport
 	localStatus  *healthModels.SelfStatus
+
+	// summary is the rolled-up connectivity count over the nodes in
+	// 'connectivity', recomputed together with the connectivity metrics so
+	// both always agree.
+	summary *healthModels.HealthStatusSummary
+
+	// history keeps recent probe outcomes per node, backing the success rate
+	// export. Has its own lock.
+	history *probeHistory
+
+	// prober is the long-lived prober driven by runActiveServices. It is
+	// nil until the active services have been started. Protected by the
+	// server lock.
+	prober *prober
 }
 
 // DumpUptime returns the time that this server has been running.
@@ -166,6 +211,115 @@ func nodeElementSliceToNodeMap(nodeElements []*models.NodeElement) nodeMap {
 	return nodes
 }
 
+// filterNodes returns the subset of nodes matching the configured
+// NodeSelector, reduced to the address families configured via
+// ProbeFamilies. With no selector and no family restriction, all nodes are
+// returned.
+func (s *Server) filterNodes(nodes nodeMap) nodeMap {
+	if s.NodeSelector != nil && len(nodes) > 0 {
+		filtered := make(nodeMap, len(nodes))
+		for ip, n := range nodes {
+			if s.NodeSelector(n.NodeElement) {
+				filtered[ip] = n
+			}
+		}
+		nodes = filtered
+	}
+	return s.filterNodeFamilies(nodes)
+}
+
+// parseProbeFamilies validates Config.ProbeFamilies and returns whether each
+// family is to be probed. An empty list enables all families.
+func parseProbeFamilies(families []string) (ipv4, ipv6 bool, err error) {
+	if len(families) == 0 {
+		return true, true, nil
+	}
+	for _, f := range families {
+		switch strings.ToLower(f) {
+		case "ipv4":
+			ipv4 = true
+		case "ipv6":
+			ipv6 = true
+		default:
+			return false, false, fmt.Errorf("invalid probe family %q, must be \"ipv4\" or \"ipv6\"", f)
+		}
+	}
+	return ipv4, ipv6, nil
+}
+
+// filterNodeFamilies reduces the node map to the probe families configured
+// via ProbeFamilies. Both the map entries and the address lists of the
+// underlying node elements are filtered, as the prober targets every address
+// a node advertises, not only the one the map is keyed by.
+func (s *Server) filterNodeFamilies(nodes nodeMap) nodeMap {
+	ipv4, ipv6, err := parseProbeFamilies(s.ProbeFamilies)
+	if err != nil || (ipv4 && ipv6) || len(nodes) == 0 {
+		// Invalid configurations are rejected by NewServer.
+		return nodes
+	}
+
+	filtered := make(nodeMap, len(nodes))
+	for ip, n := range nodes {
+		if !ipFamilyAllowed(string(ip), ipv4, ipv6) {
+			continue
+		}
+		n.NodeElement = stripDisabledFamilies(n.NodeElement, ipv4, ipv6)
+		filtered[ip] = n
+	}
+	return filtered
+}
+
+// ipFamilyAllowed returns whether the family of the given address is probed.
+// Unparseable addresses are treated as IPv4, matching the prober's fallback.
+func ipFamilyAllowed(addr string, ipv4, ipv6 bool) bool {
+	if ip := net.ParseIP(addr); ip != nil && ip.To4() == nil {
+		return ipv6
+	}
+	return ipv4
+}
+
+// stripDisabledFamilies returns a copy of the node element without the
+// addresses of families that are not probed. The input is not modified, the
+// same element may back multiple node map entries.
+func stripDisabledFamilies(n *models.NodeElement, ipv4, ipv6 bool) *models.NodeElement {
+	filtered := *n
+
+	if n.PrimaryAddress != nil {
+		pa := *n.PrimaryAddress
+		if !ipv4 {
+			pa.IPV4 = nil
+		}
+		if !ipv6 {
+			pa.IPV6 = nil
+		}
+		filtered.PrimaryAddress = &pa
+	}
+
+	if n.HealthEndpointAddress != nil {
+		ha := *n.HealthEndpointAddress
+		if !ipv4 {
+			ha.IPV4 = nil
+		}
+		if !ipv6 {
+			ha.IPV6 = nil
+		}
+		filtered.HealthEndpointAddress = &ha
+	}
+
+	if len(n.SecondaryAddresses) > 0 {
+		secondary := make([]*models.NodeAddressingElement, 0, len(n.SecondaryAddresses))
+		for _, addr := range n.SecondaryAddresses {
+			if addr != nil && !ipFamilyAllowed(addr.IP, ipv4, ipv6) {
+				continue
+			}
+			secondary = append(secondary, addr)
+		}
+		filtered.SecondaryAddresses = secondary
+	}
+
+	return &filtered
+}
+
 // updateCluster makes the specified health report visible to the API.
 //
 // It only updates the server's API-visible health report if the provided
@@ -177,6 +331,7 @@ func (s *Server) updateCluster(report *healthReport) {
 	if s.connectivity.startTime.Before(report.startTime) {
 		s.connectivity = report
 		s.collectNodeConnectivityMetrics()
+		s.recordProbeOutcomes(report)
 	}
 }
 
@@ -186,8 +341,18 @@ func (s *Server) collectNodeConnectivityMetrics() {
 	}
 	localClusterName, localNodeName := getClusterNodeName(s.localStatus.Name)
 
+	summary := &healthModels.HealthStatusSummary{
+		TotalNodes: int64(len(s.connectivity.nodes)),
+		Node:       &healthModels.ConnectivityCount{},
+		Endpoint:   &healthModels.ConnectivityCount{},
+	}
+
 	for _, n := range s.connectivity.nodes {
 		if n == nil || n.Host == nil || n.Host.PrimaryAddress == nil || n.HealthEndpoint == nil || n.HealthEndpoint.PrimaryAddress == nil {
+			// Nothing is known about this node yet, e.g. it was just
+			// added and has not been probed.
+			summary.Node.Unknown++
+			summary.Endpoint.Unknown++
 			continue
 		}
 
@@ -196,8 +361,13 @@ func (s *Server) collectNodeConnectivityMetrics() {
 		nodePathSecondaryAddress := healthClientPkg.GetHostSecondaryAddresses(n)
 
 		endpointPathStatus := n.HealthEndpoint
-		isEndpointReachable := healthClientPkg.SummarizePathConnectivityStatusType(healthClientPkg.GetAllEndpointAddresses(n)) == healthClientPkg.ConnStatusReachable
-		isNodeReachable := healthClientPkg.SummarizePathConnectivityStatusType(healthClientPkg.GetAllHostAddresses(n)) == healthClientPkg.ConnStatusReachable
+		endpointConnStatus := healthClientPkg.SummarizePathConnectivityStatusType(healthClientPkg.GetAllEndpointAddresses(n))
+		nodeConnStatus := healthClientPkg.SummarizePathConnectivityStatusType(healthClientPkg.GetAllHostAddresses(n))
+		isEndpointReachable := endpointConnStatus == healthClientPkg.ConnStatusReachable
+		isNodeReachable := nodeConnStatus == healthClientPkg.ConnStatusReachable
+
+		countConnStatus(summary.Node, nodeConnStatus)
+		countConnStatus(summary.Endpoint, endpointConnStatus)
 
 		location := metrics.LabelLocationLocalNode
 		if targetClusterName != localClusterName {
@@ -264,6 +434,21 @@ func (s *Server) collectNodeConnectivityMetrics() {
 				location, metrics.LabelPeerNode, metrics.LabelTrafficICMP, metrics.LabelAddressTypeSecondary)
 		}
 	}
+
+	s.summary = summary
+}
+
+// countConnStatus adds a node with the given aggregated connectivity status
+// to the count.
+func countConnStatus(count *healthModels.ConnectivityCount, status healthClientPkg.ConnectivityStatusType) {
+	switch status {
+	case healthClientPkg.ConnStatusReachable:
+		count.Reachable++
+	case healthClientPkg.ConnStatusUnknown:
+		count.Unknown++
+	default:
+		count.Unreachable++
+	}
 }
 
 func collectConnectivityMetric(status *healthModels.ConnectivityStatus, labels ...string) {
@@ -300,6 +485,7 @@ func (s *Server) GetStatusResponse() *healthModels.HealthStatusResponse {
 			Name: name,
 		},
 		Nodes:     s.connectivity.nodes,
+		Summary:   s.summary,
 		Timestamp: s.connectivity.startTime.Format(time.RFC3339),
 	}
 }
@@ -335,7 +521,7 @@ func (s *Server) runActiveServices() error {
 	// We can safely ignore nodesRemoved since it's the first time we are
 	// fetching the nodes from the server.
 	nodesAdded, _, _ := s.getNodes()
-	prober := newProber(s, nodesAdded)
+	prober := newProber(s, s.filterNodes(nodesAdded))
 	prober.MaxRTT = s.ProbeInterval
 	prober.OnIdle = func() {
 		// OnIdle is called every ProbeInterval after sending out all icmp pings.
@@ -357,19 +543,44 @@ func (s *Server) runActiveServices() error {
 			prober.updateIcmpStatus()
 			// (2) setNodes implementation doesn't override results for existing nodes.
 			// (4) Remove stale nodes so we don't report them in metrics before updating results
-			prober.setNodes(nodesAdded, nodesRemoved)
+			prober.setNodes(s.filterNodes(nodesAdded), nodesRemoved)
 			// (4) Update results without stale nodes
 			s.updateCluster(prober.getResults())
 			// (3) Cleanup icmp results for next iteration of probing
 			prober.clearIcmpStatus()
 		}
 	}
+	s.Lock()
+	s.prober = prober
+	s.Unlock()
+
 	prober.RunLoop()
 	defer prober.Stop()
 
 	return s.Server.Serve()
 }
 
+// ProbeNow triggers an immediate probe cycle on the long-lived prober and
+// refreshes the connectivity report from the fresh results. It blocks until
+// the probe cycle has completed or the probe interval has elapsed. Concurrent
+// calls coalesce into a single probe cycle.
+func (s *Server) ProbeNow() error {
+	s.RLock()
+	prober := s.prober
+	s.RUnlock()
+
+	if prober == nil {
+		return fmt.Errorf("prober is not running")
+	}
+
+	if err := prober.RunProbeNow(s.ProbeInterval); err != nil {
+		return err
+	}
+	s.updateCluster(prober.getResults())
+
+	return nil
+}
+
 // Serve spins up the following goroutines:
 //   - HTTP API Server: Responder to the health API "/hello" message
 //   - Prober: Periodically run pings across the cluster at a configured interval
@@ -417,15 +628,33 @@ func (s *Server) newServer(spec *healthApi.Spec) *healthApi.Server {
 
 	srv.ConfigureAPI()
 
+	// Serve the flat per-node latency and success rate exports next to the
+	// generated API.
+	srv.SetHandler(s.latenciesHandler(s.successRatesHandler(s.probeNowHandler(srv.GetHandler()))))
+
 	return srv
 }
 
 // NewServer creates a server to handle health requests.
 func NewServer(config Config) (*Server, error) {
+	if config.HTTPProbePayloadBytes < 0 || config.HTTPProbePayloadBytes > MaxHTTPProbePayloadBytes {
+		return nil, fmt.Errorf("invalid HTTP probe payload size %d: must be within [0, %d]",
+			config.HTTPProbePayloadBytes, MaxHTTPProbePayloadBytes)
+	}
+
+	if _, _, err := parseProbeFamilies(config.ProbeFamilies); err != nil {
+		return nil, err
+	}
+
+	if config.ProbeHistoryDepth < 0 {
+		return nil, fmt.Errorf("invalid probe history depth %d: must not be negative", config.ProbeHistoryDepth)
+	}
+
 	server := &Server{
 		startTime:    time.Now(),
 		Config:       config,
 		connectivity: &healthReport{},
+		history:      newProbeHistory(config.ProbeHistoryDepth),
 	}
 
 	cl, err := ciliumPkg.NewClient(config.CiliumURI)
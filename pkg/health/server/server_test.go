@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	healthModels "github.com/cilium/cilium/api/v1/health/models"
+	"github.com/cilium/cilium/api/v1/models"
 	"github.com/cilium/cilium/pkg/metrics"
 	"github.com/cilium/cilium/pkg/metrics/metric"
 )
@@ -333,3 +334,82 @@ func Test_server_collectNodeConnectivityMetrics(t *testing.T) {
 	}
 
 }
+
+func TestFilterNodes(t *testing.T) {
+	nodes := nodeMap{
+		"10.0.0.1": NewHealthNode(&models.NodeElement{Name: "cluster/node1"}),
+		"10.0.0.2": NewHealthNode(&models.NodeElement{Name: "cluster/node2"}),
+	}
+
+	// Without a selector, the node map is returned as-is.
+	s := &Server{}
+	require.Equal(t, nodes, s.filterNodes(nodes))
+
+	// A selector restricts the nodes handed to the prober.
+	s.NodeSelector = func(n *models.NodeElement) bool {
+		return n.Name == "cluster/node1"
+	}
+	filtered := s.filterNodes(nodes)
+	require.Len(t, filtered, 1)
+	require.Contains(t, filtered, ipString("10.0.0.1"))
+
+	// A selector matching no nodes yields an empty map, probing nothing.
+	s.NodeSelector = func(*models.NodeElement) bool { return false }
+	require.Empty(t, s.filterNodes(nodes))
+}
+
+func TestFilterNodeFamilies(t *testing.T) {
+	node1 := &models.NodeElement{
+		Name: "cluster/node1",
+		PrimaryAddress: &models.NodeAddressing{
+			IPV4: &models.NodeAddressingElement{IP: "10.0.0.1"},
+			IPV6: &models.NodeAddressingElement{IP: "fd00::1"},
+		},
+		SecondaryAddresses: []*models.NodeAddressingElement{
+			{IP: "10.0.1.1"},
+			{IP: "fd00:1::1"},
+		},
+	}
+	nodes := nodeMap{
+		"10.0.0.1": NewHealthNode(node1),
+		"fd00::1":  NewHealthNode(node1),
+	}
+
+	// With no restriction, the node map is returned as-is.
+	s := &Server{}
+	require.Equal(t, nodes, s.filterNodes(nodes))
+
+	// Probing IPv4 only drops IPv6 map entries and addresses.
+	s.ProbeFamilies = []string{"ipv4"}
+	filtered := s.filterNodes(nodes)
+	require.Len(t, filtered, 1)
+	require.Contains(t, filtered, ipString("10.0.0.1"))
+	elem := filtered["10.0.0.1"].NodeElement
+	require.NotNil(t, elem.PrimaryAddress.IPV4)
+	require.Nil(t, elem.PrimaryAddress.IPV6)
+	require.Len(t, elem.SecondaryAddresses, 1)
+	require.Equal(t, "10.0.1.1", elem.SecondaryAddresses[0].IP)
+
+	// The original element is left untouched for other consumers.
+	require.NotNil(t, node1.PrimaryAddress.IPV6)
+	require.Len(t, node1.SecondaryAddresses, 2)
+
+	// Naming both families is equivalent to no restriction.
+	s.ProbeFamilies = []string{"ipv4", "ipv6"}
+	require.Equal(t, nodes, s.filterNodes(nodes))
+}
+
+func TestParseProbeFamilies(t *testing.T) {
+	ipv4, ipv6, err := parseProbeFamilies(nil)
+	require.NoError(t, err)
+	require.True(t, ipv4)
+	require.True(t, ipv6)
+
+	ipv4, ipv6, err = parseProbeFamilies([]string{"IPv6"})
+	require.NoError(t, err)
+	require.False(t, ipv4)
+	require.True(t, ipv6)
+
+	_, _, err = parseProbeFamilies([]string{"ipv5"})
+	require.Error(t, err)
+}
@@ -7,6 +7,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -44,6 +46,7 @@ func ParseMetricList(enabledMetrics []string) (m Map) {
 
 // Handlers contains all the metrics handlers.
 type Handlers struct {
+	names          []string
 	handlers       []Handler
 	flowProcessors []FlowProcessor
 }
@@ -97,6 +100,7 @@ type FlowProcessor interface {
 func NewHandlers(log logrus.FieldLogger, registry *prometheus.Registry, in []NamedHandler) (*Handlers, error) {
 	var handlers Handlers
 	for _, item := range in {
+		handlers.names = append(handlers.names, item.Name)
 		handlers.handlers = append(handlers.handlers, item.Handler)
 		if fp, ok := item.Handler.(FlowProcessor); ok {
 			handlers.flowProcessors = append(handlers.flowProcessors, fp)
@@ -111,9 +115,16 @@ func NewHandlers(log logrus.FieldLogger, registry *prometheus.Registry, in []Nam
 			"status": item.Handler.Status(),
 		}).Info("Configured metrics plugin")
 	}
+	sort.Strings(handlers.names)
+	log.WithField("handlers", handlers.names).Info("Configured metrics handlers")
 	return &handlers, nil
 }
 
+// Names returns the names of the enabled metric handlers in sorted order.
+func (h Handlers) Names() []string {
+	return slices.Clone(h.names)
+}
+
 // ProcessFlow processes a flow by calling ProcessFlow it on to all enabled
 // metric handlers
 func (h Handlers) ProcessFlow(ctx context.Context, flow *pb.Flow) error {
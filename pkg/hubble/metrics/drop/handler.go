@@ -5,6 +5,9 @@ package drop
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -16,6 +19,12 @@ import (
 type dropHandler struct {
 	drops   *prometheus.CounterVec
 	context *api.ContextOptions
+
+	// reasonCodes, when non-nil, enables the "reason_code" label carrying
+	// the numeric drop reason of the flow. Only the allowlisted codes are
+	// reported verbatim; all others are aggregated under "other" to bound
+	// the cardinality of the metric.
+	reasonCodes map[uint32]struct{}
 }
 
 func (d *dropHandler) Init(registry *prometheus.Registry, options api.Options) error {
@@ -25,8 +34,30 @@ func (d *dropHandler) Init(registry *prometheus.Registry, options api.Options) e
 	}
 	d.context = c
 
+	for key, value := range options {
+		switch strings.ToLower(key) {
+		case "reason-code":
+			codes := map[uint32]struct{}{}
+			for _, s := range strings.Split(value, ",") {
+				s = strings.TrimSpace(s)
+				if s == "" {
+					continue
+				}
+				code, err := strconv.ParseUint(s, 10, 32)
+				if err != nil {
+					return fmt.Errorf("invalid drop reason code %q: %w", s, err)
+				}
+				codes[uint32(code)] = struct{}{}
+			}
+			d.reasonCodes = codes
+		}
+	}
+
 	contextLabels := d.context.GetLabelNames()
 	labels := append(contextLabels, "reason", "protocol")
+	if d.reasonCodes != nil {
+		labels = append(labels, "reason_code")
+	}
 
 	d.drops = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: api.DefaultPrometheusNamespace,
@@ -61,6 +92,14 @@ func (d *dropHandler) ProcessFlow(ctx context.Context, flow *flowpb.Flow) error
 	}
 
 	labels := append(contextLabels, flow.GetDropReasonDesc().String(), v1.FlowProtocol(flow))
+	if d.reasonCodes != nil {
+		code := flow.GetDropReason()
+		reasonCode := "other"
+		if _, ok := d.reasonCodes[code]; ok {
+			reasonCode = strconv.FormatUint(uint64(code), 10)
+		}
+		labels = append(labels, reasonCode)
+	}
 
 	d.drops.WithLabelValues(labels...).Inc()
 	return nil
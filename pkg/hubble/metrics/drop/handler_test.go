@@ -5,6 +5,7 @@ package drop
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -94,3 +95,57 @@ func TestDropHandler(t *testing.T) {
 		assert.Equal(t, 2., *metric.Counter.Value)
 	})
 }
+
+func TestDropHandlerReasonCode(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	opts := api.Options{
+		"sourceContext": "namespace",
+		"reason-code":   fmt.Sprintf("%d", uint32(pb.DropReason_POLICY_DENIED)),
+	}
+
+	dropHandler := &dropHandler{}
+	require.NoError(t, dropHandler.Init(registry, opts))
+
+	newFlow := func(reason pb.DropReason) *pb.Flow {
+		return &pb.Flow{
+			EventType: &pb.CiliumEventType{Type: monitorAPI.MessageTypePolicyVerdict},
+			L4: &pb.Layer4{
+				Protocol: &pb.Layer4_TCP{
+					TCP: &pb.TCP{},
+				},
+			},
+			Source:         &pb.Endpoint{Namespace: "foo"},
+			Verdict:        pb.Verdict_DROPPED,
+			DropReason:     uint32(reason),
+			DropReasonDesc: reason,
+		}
+	}
+
+	// An allowlisted reason is reported with its numeric code, any other
+	// reason is aggregated under "other".
+	dropHandler.ProcessFlow(context.TODO(), newFlow(pb.DropReason_POLICY_DENIED))
+	dropHandler.ProcessFlow(context.TODO(), newFlow(pb.DropReason_INVALID_SOURCE_IP))
+
+	metricFamilies, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, metricFamilies, 1)
+	require.Len(t, metricFamilies[0].Metric, 2)
+
+	codes := map[string]string{}
+	for _, metric := range metricFamilies[0].Metric {
+		var reason, code string
+		for _, label := range metric.Label {
+			switch *label.Name {
+			case "reason":
+				reason = *label.Value
+			case "reason_code":
+				code = *label.Value
+			}
+		}
+		codes[reason] = code
+	}
+	assert.Equal(t, map[string]string{
+		"POLICY_DENIED":     fmt.Sprintf("%d", uint32(pb.DropReason_POLICY_DENIED)),
+		"INVALID_SOURCE_IP": "other",
+	}, codes)
+}
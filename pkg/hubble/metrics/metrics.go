@@ -37,6 +37,10 @@ import (
 type CiliumEndpointDeletionHandler struct {
 	gracefulPeriod time.Duration
 	queue          workqueue.DelayingInterface
+
+	// drained is closed once the drain goroutine has exited, i.e. after
+	// the queue was shut down and all remaining items were processed.
+	drained chan struct{}
 }
 
 var (
@@ -46,34 +50,70 @@ var (
 )
 
 // Additional metrics - they're not counting flows, so are not served via
-// Hubble metrics API, but belong to the same Prometheus namespace.
+// Hubble metrics API, but belong to the same Prometheus namespace by default.
+// They are constructed with the default namespace up front so that callers can
+// reference them at any time, and are rebuilt in initMetrics if a different
+// namespace has been configured.
 var (
-	labelSource = "source"
-	LostEvents  = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Namespace: api.DefaultPrometheusNamespace,
-		Name:      "lost_events_total",
-		Help:      "Number of lost events",
-	}, []string{labelSource})
+	labelSource            = "source"
+	LostEvents             = newLostEventsCounter(api.DefaultPrometheusNamespace)
+	FlowProcessingDuration = newFlowProcessingHistogram(api.DefaultPrometheusNamespace)
+	EnabledMetricHandlers  = newEnabledMetricHandlersGauge(api.DefaultPrometheusNamespace)
 )
 
 // Metrics related to Hubble metrics HTTP requests handling
 var (
-	RequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Namespace: api.DefaultPrometheusNamespace,
+	RequestsTotal   = newRequestsTotalCounter(api.DefaultPrometheusNamespace)
+	RequestDuration = newRequestDurationHistogram(api.DefaultPrometheusNamespace)
+)
+
+func newLostEventsCounter(namespace string) *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "lost_events_total",
+		Help:      "Number of lost events",
+	}, []string{labelSource})
+}
+
+func newFlowProcessingHistogram(namespace string) prometheus.Histogram {
+	return prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "metrics_flow_processing_duration_seconds",
+		Help:      "A histogram of the time taken by the enabled metric handlers to process a flow.",
+	})
+}
+
+func newEnabledMetricHandlersGauge(namespace string) prometheus.Gauge {
+	return prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "metrics_enabled_handlers",
+		Help:      "The number of enabled metric handlers",
+	})
+}
+
+func newRequestsTotalCounter(namespace string) *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
 		Name:      "metrics_http_handler_requests_total",
 		Help:      "A counter for requests to Hubble metrics handler.",
 	}, []string{"code"})
-	RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-		Namespace: api.DefaultPrometheusNamespace,
+}
+
+func newRequestDurationHistogram(namespace string) *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
 		Name:      "metrics_http_handler_request_duration_seconds",
 		Help:      "A histogram of latencies of Hubble metrics handler.",
 	}, []string{"code"})
-)
+}
 
 // ProcessFlow processes a flow and updates metrics
 func ProcessFlow(ctx context.Context, flow *pb.Flow) error {
 	if enabledMetrics != nil {
-		return enabledMetrics.ProcessFlow(ctx, flow)
+		start := time.Now()
+		err := enabledMetrics.ProcessFlow(ctx, flow)
+		FlowProcessingDuration.Observe(time.Since(start).Seconds())
+		return err
 	}
 	return nil
 }
@@ -118,9 +158,11 @@ func initEndpointDeletionHandler() {
 	endpointDeletionHandler = &CiliumEndpointDeletionHandler{
 		gracefulPeriod: time.Minute,
 		queue:          workqueue.NewDelayingQueue(),
+		drained:        make(chan struct{}),
 	}
 
 	go func() {
+		defer close(endpointDeletionHandler.drained)
 		for {
 			endpoint, quit := endpointDeletionHandler.queue.Get()
 			if quit {
@@ -132,18 +174,52 @@ func initEndpointDeletionHandler() {
 	}()
 }
 
+// ShutdownEndpointDeletionHandler shuts down the endpoint deletion queue and
+// waits until the drain goroutine has processed the items still queued, so
+// that per-endpoint metric series are cleaned up before exit. The wait is
+// bounded by the given context. Safe to call when the handler was never
+// initialized.
+func ShutdownEndpointDeletionHandler(ctx context.Context) error {
+	handler := endpointDeletionHandler
+	if handler == nil {
+		return nil
+	}
+
+	// ShutDownWithDrain blocks until all items in flight are marked done,
+	// run it in the background so the context bound applies to it as well.
+	go handler.queue.ShutDownWithDrain()
+
+	select {
+	case <-handler.drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // initMetrics initializes the metrics system
-func initMetrics(address string, metricsTLSConfig *certloader.WatchedServerConfig, enabled api.Map, grpcMetrics *grpc_prometheus.ServerMetrics, enableOpenMetrics bool) (<-chan error, error) {
+func initMetrics(address string, metricsTLSConfig *certloader.WatchedServerConfig, enabled api.Map, grpcMetrics *grpc_prometheus.ServerMetrics, enableOpenMetrics bool, namespace string) (<-chan error, error) {
 	e, err := initMetricHandlers(enabled)
 	if err != nil {
 		return nil, err
 	}
 	enabledMetrics = e
 
+	if namespace != "" && namespace != api.DefaultPrometheusNamespace {
+		LostEvents = newLostEventsCounter(namespace)
+		FlowProcessingDuration = newFlowProcessingHistogram(namespace)
+		EnabledMetricHandlers = newEnabledMetricHandlersGauge(namespace)
+		RequestsTotal = newRequestsTotalCounter(namespace)
+		RequestDuration = newRequestDurationHistogram(namespace)
+	}
+
 	registry.MustRegister(grpcMetrics)
 	registry.MustRegister(LostEvents)
+	registry.MustRegister(FlowProcessingDuration)
+	registry.MustRegister(EnabledMetricHandlers)
 	registry.MustRegister(RequestsTotal)
 	registry.MustRegister(RequestDuration)
+	EnabledMetricHandlers.Set(float64(len(e.Names())))
 
 	errChan := make(chan error, 1)
 
@@ -154,9 +230,11 @@ func initMetrics(address string, metricsTLSConfig *certloader.WatchedServerConfi
 }
 
 // EnableMetrics starts the metrics server with a given list of metrics. This is the
-// function Cilium uses to configure Hubble metrics in embedded mode.
-func EnableMetrics(log logrus.FieldLogger, metricsServer string, metricsTLSConfig *certloader.WatchedServerConfig, m []string, grpcMetrics *grpc_prometheus.ServerMetrics, enableOpenMetrics bool) error {
-	errChan, err := initMetrics(metricsServer, metricsTLSConfig, api.ParseMetricList(m), grpcMetrics, enableOpenMetrics)
+// function Cilium uses to configure Hubble metrics in embedded mode. The
+// namespace overrides the Prometheus namespace of the auxiliary metrics; an
+// empty value keeps api.DefaultPrometheusNamespace.
+func EnableMetrics(log logrus.FieldLogger, metricsServer string, metricsTLSConfig *certloader.WatchedServerConfig, m []string, grpcMetrics *grpc_prometheus.ServerMetrics, enableOpenMetrics bool, namespace string) error {
+	errChan, err := initMetrics(metricsServer, metricsTLSConfig, api.ParseMetricList(m), grpcMetrics, enableOpenMetrics, namespace)
 	if err != nil {
 		return fmt.Errorf("unable to setup metrics: %w", err)
 	}
@@ -169,6 +247,33 @@ func EnableMetrics(log logrus.FieldLogger, metricsServer string, metricsTLSConfi
 	return nil
 }
 
+// EnableMetricsOnRegistry configures the enabled metric handlers on the given
+// external Prometheus registry instead of starting a standalone metrics
+// server. This is used in embedded mode to expose Hubble metrics on the
+// agent's main metrics endpoint, avoiding a second scrape target. The
+// HTTP-handler metrics (RequestsTotal, RequestDuration) are not registered as
+// no Hubble-owned server is running.
+func EnableMetricsOnRegistry(reg *prometheus.Registry, m []string) error {
+	// Repoint the package registry so that subsequent Register calls attach
+	// to the external registry as well.
+	registry = reg
+
+	e, err := initMetricHandlers(api.ParseMetricList(m))
+	if err != nil {
+		return fmt.Errorf("unable to setup metrics: %w", err)
+	}
+	enabledMetrics = e
+
+	registry.MustRegister(LostEvents)
+	registry.MustRegister(FlowProcessingDuration)
+	registry.MustRegister(EnabledMetricHandlers)
+	EnabledMetricHandlers.Set(float64(len(e.Names())))
+
+	initEndpointDeletionHandler()
+
+	return nil
+}
+
 // Register registers additional metrics collectors within hubble metrics registry.
 func Register(cs ...prometheus.Collector) {
 	registry.MustRegister(cs...)
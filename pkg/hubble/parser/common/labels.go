@@ -8,47 +8,128 @@ import (
 	"sort"
 	"strings"
 
+	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/sirupsen/logrus"
 
 	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/labels"
 )
 
+// cidrLabelCacheSize bounds the number of parsed cidr labels kept around.
+// CIDR-heavy identities repeat the same handful of labels on every flow, so a
+// small bounded cache avoids re-running net.ParseCIDR in the hot path while
+// adversarial label churn cannot grow it beyond this limit.
+const cidrLabelCacheSize = 2048
+
+type parsedCIDRLabel struct {
+	maskSize int
+	isIPv4   bool
+}
+
+var cidrLabelCache, _ = lru.New[string, parsedCIDRLabel](cidrLabelCacheSize)
+
+// parseCIDRLabel parses the value of a cidr: label, returning the prefix
+// length and address family. Successful results are served from a bounded
+// cache; invalid labels are not cached so that they keep producing a warning.
+func parseCIDRLabel(value string) (parsedCIDRLabel, bool) {
+	if parsed, ok := cidrLabelCache.Get(value); ok {
+		return parsed, true
+	}
+	// labels for IPv6 addresses are represented with - instead of : as
+	// : cannot be used in labels; make sure to convert it to a valid
+	// IPv6 representation
+	_, curr, err := net.ParseCIDR(strings.Replace(value, "-", ":", -1))
+	if err != nil {
+		return parsedCIDRLabel{}, false
+	}
+	maskSize, _ := curr.Mask.Size()
+	parsed := parsedCIDRLabel{
+		maskSize: maskSize,
+		isIPv4:   curr.IP.To4() != nil,
+	}
+	cidrLabelCache.Add(value, parsed)
+	return parsed, true
+}
+
 func FilterCIDRLabels(log logrus.FieldLogger, labels []string) []string {
 	// Cilium might return a bunch of cidr labels with different prefix length. Filter out all
 	// but the longest prefix cidr label, which can be useful for troubleshooting. This also
 	// relies on the fact that when a Cilium security identity has multiple CIDR labels, longer
-	// prefix is always a subset of shorter prefix.
+	// prefix is always a subset of shorter prefix. The longest prefix is tracked separately
+	// per address family, so that a dual-stack identity keeps its most-specific IPv4 and
+	// IPv6 label.
 	cidrPrefix := "cidr:"
+	// For world identities the cidr labels are redundant with the reserved
+	// labels and only inflate cardinality, so drop them entirely.
+	if hasWorldLabel(labels) {
+		var filteredLabels []string
+		for _, label := range labels {
+			if !strings.HasPrefix(label, cidrPrefix) {
+				filteredLabels = append(filteredLabels, label)
+			}
+		}
+		return filteredLabels
+	}
 	var filteredLabels []string
-	var maxSize int
-	var maxStr string
+	var maxSize4, maxSize6 int
+	var maxStr4, maxStr6 string
 	for _, label := range labels {
 		if !strings.HasPrefix(label, cidrPrefix) {
 			filteredLabels = append(filteredLabels, label)
 			continue
 		}
-		currLabel := strings.TrimPrefix(label, cidrPrefix)
-		// labels for IPv6 addresses are represented with - instead of : as
-		// : cannot be used in labels; make sure to convert it to a valid
-		// IPv6 representation
-		currLabel = strings.Replace(currLabel, "-", ":", -1)
-		_, curr, err := net.ParseCIDR(currLabel)
-		if err != nil {
+		parsed, ok := parseCIDRLabel(strings.TrimPrefix(label, cidrPrefix))
+		if !ok {
 			log.WithField("label", label).Warn("got an invalid cidr label")
 			continue
 		}
-		if currMask, _ := curr.Mask.Size(); currMask > maxSize {
-			maxSize, maxStr = currMask, label
+		if parsed.isIPv4 {
+			if parsed.maskSize > maxSize4 {
+				maxSize4, maxStr4 = parsed.maskSize, label
+			}
+		} else if parsed.maskSize > maxSize6 {
+			maxSize6, maxStr6 = parsed.maskSize, label
 		}
 	}
-	if maxSize != 0 {
-		filteredLabels = append(filteredLabels, maxStr)
+	if maxSize4 != 0 {
+		filteredLabels = append(filteredLabels, maxStr4)
+	}
+	if maxSize6 != 0 {
+		filteredLabels = append(filteredLabels, maxStr6)
 	}
 	return filteredLabels
 }
 
+// hasWorldLabel returns true if the given label set contains one of the
+// reserved world labels.
+func hasWorldLabel(lbls []string) bool {
+	for _, label := range lbls {
+		switch label {
+		case labels.LabelSourceReserved + ":" + labels.IDNameWorld,
+			labels.LabelSourceReserved + ":" + labels.IDNameWorldIPv4,
+			labels.LabelSourceReserved + ":" + labels.IDNameWorldIPv6:
+			return true
+		}
+	}
+	return false
+}
+
+// SortAndFilterLabelsOptions controls the behavior of
+// SortAndFilterLabelsWithOptions.
+type SortAndFilterLabelsOptions struct {
+	// KeepAllCIDRLabels disables collapsing of cidr: labels to the most
+	// specific prefix, preserving the full CIDR hierarchy. Useful for
+	// debug output; the default flow path keeps collapsing enabled to
+	// stay compact.
+	KeepAllCIDRLabels bool
+}
+
 func SortAndFilterLabels(log logrus.FieldLogger, labels []string, securityIdentity identity.NumericIdentity) []string {
-	if securityIdentity.HasLocalScope() {
+	return SortAndFilterLabelsWithOptions(log, labels, securityIdentity, SortAndFilterLabelsOptions{})
+}
+
+func SortAndFilterLabelsWithOptions(log logrus.FieldLogger, labels []string, securityIdentity identity.NumericIdentity, opts SortAndFilterLabelsOptions) []string {
+	if securityIdentity.HasLocalScope() && !opts.KeepAllCIDRLabels {
 		labels = FilterCIDRLabels(log, labels)
 	}
 	sort.Strings(labels)
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+package common
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterCIDRLabels(t *testing.T) {
+	log := logrus.New()
+
+	labels := []string{
+		"k8s:app=foo",
+		"cidr:10.0.0.0/8",
+		"cidr:10.0.0.0/16",
+		"cidr:10.0.1.0/24",
+		"cidr:f00d--0/64",
+		"cidr:f00d--0/112",
+		"cidr:invalid",
+	}
+	// Only the longest prefix per address family is kept, invalid cidr
+	// labels are dropped.
+	filtered := FilterCIDRLabels(log, labels)
+	assert.Equal(t, []string{"k8s:app=foo", "cidr:10.0.1.0/24", "cidr:f00d--0/112"}, filtered)
+
+	// Run a second time to exercise the cached path.
+	filtered = FilterCIDRLabels(log, labels)
+	assert.Equal(t, []string{"k8s:app=foo", "cidr:10.0.1.0/24", "cidr:f00d--0/112"}, filtered)
+}
+
+func benchmarkFilterCIDRLabels(b *testing.B, cached bool) {
+	log := logrus.New()
+
+	labels := make([]string, 0, 20)
+	for i := 0; i < 20; i++ {
+		labels = append(labels, fmt.Sprintf("cidr:10.%d.0.0/%d", i, 8+i))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !cached {
+			cidrLabelCache.Purge()
+		}
+		FilterCIDRLabels(log, labels)
+	}
+}
+
+func BenchmarkFilterCIDRLabels(b *testing.B) {
+	b.Run("cached", func(b *testing.B) { benchmarkFilterCIDRLabels(b, true) })
+	b.Run("uncached", func(b *testing.B) { benchmarkFilterCIDRLabels(b, false) })
+}
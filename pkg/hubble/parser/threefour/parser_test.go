@@ -1050,6 +1050,19 @@ func Test_filterCIDRLabels(t *testing.T) {
 				},
 			},
 			want: []string{"b", "a", "d", "cidr:2a00-1450-400a-800--0/107"},
+		}, {
+			name: "mixed, dual-stack",
+			args: args{
+				labels: []string{
+					"b",
+					"cidr:1.1.1.1/23",
+					"cidr:2a00-1450-400a-800--0/85", // - is used instead of : in the address because labels cannot contain :
+					"a",
+					"cidr:1.1.1.1/24",
+					"cidr:2a00-1450-400a-800--0/107",
+				},
+			},
+			want: []string{"b", "a", "cidr:1.1.1.1/24", "cidr:2a00-1450-400a-800--0/107"},
 		}, {
 			name: "no-cidr",
 			args: args{
@@ -1076,6 +1089,26 @@ func Test_filterCIDRLabels(t *testing.T) {
 				},
 			},
 			want: []string{"cidr:2a00-1450-400a-800--0/107"},
+		}, {
+			name: "world",
+			args: args{
+				labels: []string{
+					"reserved:world",
+					"cidr:1.1.1.1/24",
+					"cidr:1.1.1.1/23",
+				},
+			},
+			want: []string{"reserved:world"},
+		}, {
+			name: "world-ipv6",
+			args: args{
+				labels: []string{
+					"reserved:world-ipv6",
+					"cidr:2a00-1450-400a-800--0/85", // - is used instead of : in the address because labels cannot contain :
+					"cidr:2a00-1450-400a-800--0/107",
+				},
+			},
+			want: []string{"reserved:world-ipv6"},
 		}, {
 			name: "empty",
 			args: args{
@@ -102,6 +102,15 @@ type Service struct {
 	//
 	// +kubebuilder:validation:Optional
 	Ports []string `json:"number,omitempty"`
+
+	// TLSSecret is the name of a TLS secret, optionally prefixed with a
+	// namespace followed by a '/', to use for upstream TLS towards this
+	// service's backends. If set, an upstream TLS transport socket
+	// referencing the secret via SDS is injected into the clusters
+	// generated for this service, unless they already configure one.
+	//
+	// +kubebuilder:validation:Optional
+	TLSSecret string `json:"tlsSecret,omitempty"`
 }
 
 type ServiceListener struct {
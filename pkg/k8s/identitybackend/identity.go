@@ -60,7 +60,8 @@ type crdBackend struct {
 	CRDBackendConfiguration
 }
 
-func (c *crdBackend) DeleteAllKeys(ctx context.Context) {
+func (c *crdBackend) DeleteAllKeys(ctx context.Context) (int, error) {
+	return 0, nil
 }
 
 // SanitizeK8sLabels strips the 'k8s:' prefix in the labels generated by
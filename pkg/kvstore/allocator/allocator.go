@@ -5,10 +5,14 @@ package allocator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand/v2"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -24,6 +28,18 @@ var (
 	log = logging.DefaultLogger.WithField(logfields.LogSubsys, "kvstorebackend")
 )
 
+const (
+	// defaultUpdateKeyRetries is the default number of retries for the
+	// kvstore writes of UpdateKey, see
+	// KVStoreBackendConfiguration.UpdateKeyRetries.
+	defaultUpdateKeyRetries = 3
+
+	// defaultUpdateKeyRetryBaseDelay is the default delay before the first
+	// UpdateKey retry, see
+	// KVStoreBackendConfiguration.UpdateKeyRetryBaseDelay.
+	defaultUpdateKeyRetryBaseDelay = 50 * time.Millisecond
+)
+
 // kvstoreBackend is an implementaton of pkg/allocator.Backend. It store
 // identities in the following format:
 //
@@ -75,6 +91,38 @@ type kvstoreBackend struct {
 	backend kvstore.BackendOperations
 
 	keyType allocator.AllocatorKey
+
+	// idEncoder converts IDs to the path component of master keys, see
+	// IDEncoder.
+	idEncoder IDEncoder
+
+	// lockTTL is the lock-specific lease TTL, see
+	// KVStoreBackendConfiguration.LockTTL. Zero uses the default lock
+	// lease.
+	lockTTL time.Duration
+
+	// gcDeleteJitterFraction adds a random extra delay of up to this
+	// fraction of the rate limited wait after each RunGC delete, see
+	// gcDeleteWait.
+	gcDeleteJitterFraction float64
+
+	// updateKeyRetries is the number of times the kvstore writes of
+	// UpdateKey are retried on transient errors, see
+	// KVStoreBackendConfiguration.UpdateKeyRetries.
+	updateKeyRetries int
+
+	// updateKeyRetryBaseDelay is the delay before the first UpdateKey
+	// retry, doubled on each further retry.
+	updateKeyRetryBaseDelay time.Duration
+
+	// tombstonePrefix is the kvstore key prefix for the tombstone markers
+	// written when tombstone mode is enabled, see
+	// KVStoreBackendConfiguration.TombstoneTTL.
+	tombstonePrefix string
+
+	// tombstoneTTL enables tombstone mode when non-zero, see
+	// KVStoreBackendConfiguration.TombstoneTTL.
+	tombstoneTTL time.Duration
 }
 
 func prefixMatchesKey(prefix, key string) bool {
@@ -83,39 +131,246 @@ func prefixMatchesKey(prefix, key string) bool {
 	return len(prefix) == lastSlash
 }
 
+// IDEncoder converts between allocator IDs and the path component used for
+// master keys below the ID prefix. The default encoding is the plain base-10
+// representation of the ID; backends which rely on lexicographic ordering of
+// ListPrefix results can plug in a fixed-width zero-padded encoding instead.
+// All agents sharing a kvstore must use the same encoding.
+type IDEncoder interface {
+	// EncodeID returns the path component for the given ID.
+	EncodeID(id idpool.ID) string
+	// DecodeID parses a path component produced by EncodeID.
+	DecodeID(s string) (idpool.ID, error)
+}
+
+// base10IDEncoder is the default encoding, the plain base-10 representation
+// as produced by idpool.ID.String().
+type base10IDEncoder struct{}
+
+func (base10IDEncoder) EncodeID(id idpool.ID) string { return id.String() }
+
+func (base10IDEncoder) DecodeID(s string) (idpool.ID, error) {
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return idpool.NoID, err
+	}
+	return idpool.ID(id), nil
+}
+
+// ZeroPadIDEncoder encodes IDs as base-10 strings zero-padded to Width
+// digits, so that ListPrefix returns master keys in numeric order.
+type ZeroPadIDEncoder struct {
+	// Width is the minimum number of digits; shorter IDs are left-padded
+	// with zeros.
+	Width int
+}
+
+func (e ZeroPadIDEncoder) EncodeID(id idpool.ID) string {
+	return fmt.Sprintf("%0*d", e.Width, uint64(id))
+}
+
+func (e ZeroPadIDEncoder) DecodeID(s string) (idpool.ID, error) {
+	// ParseUint accepts leading zeros, so padded and unpadded forms decode
+	// alike.
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return idpool.NoID, err
+	}
+	return idpool.ID(id), nil
+}
+
+// KVStoreBackendConfiguration contains the configuration of a kvstore backend.
+type KVStoreBackendConfiguration struct {
+	// BasePath is the prefix in the kvstore that all keys share, see
+	// kvstoreBackend.basePrefix.
+	BasePath string
+	// Suffix is attached to keys which must be node specific, typically
+	// the node's IP address.
+	Suffix string
+	// Typ is the type of the allocator keys managed by this backend. If
+	// nil, the backend does not create slave keys and an empty Suffix is
+	// tolerated.
+	Typ allocator.AllocatorKey
+	// Backend is the kvstore client to use.
+	Backend kvstore.BackendOperations
+	// SuffixValidator, if non-nil, is invoked to validate the Suffix.
+	// Deployments using cluster-qualified suffixes can enforce their own
+	// format here.
+	SuffixValidator func(suffix string) error
+	// GCDeleteJitterFraction, if non-zero, randomizes the wait between
+	// RunGC deletes by up to this fraction of the rate limited wait, so
+	// that GC rounds of many agents desynchronize. Must be in [0, 1].
+	GCDeleteJitterFraction float64
+	// IDEncoder, if non-nil, controls how IDs are encoded into master key
+	// paths. Nil preserves the default base-10 encoding.
+	IDEncoder IDEncoder
+	// LockTTL, if non-zero, requests a lock-specific lease TTL for the
+	// locks taken by this backend, decoupling them from the default lock
+	// lease. GC rounds listing the slave keys of slow backends can outlast
+	// the default lease and lose the lock mid-run; a larger LockTTL lets
+	// the lock survive such list operations. The local staleness detection
+	// of kvstore.RunLockGC is unaffected by this setting and still
+	// force-releases locks held longer than
+	// defaults.KVStoreStaleLockTimeout, so LockTTL must stay below that
+	// timeout.
+	LockTTL time.Duration
+	// ClusterName, if non-empty, scopes all prefixes of this backend to
+	// the given cluster by inserting a "clusters/<name>" segment between
+	// BasePath and the id/value/locks prefixes. This allows multiple
+	// backends of the same type to manage per-cluster identity spaces
+	// below a shared BasePath without path collisions. The name must not
+	// contain a path separator.
+	ClusterName string
+	// ClusterID, if non-zero and ClusterName is empty, scopes the
+	// prefixes by the numeric cluster ID instead of the cluster name.
+	ClusterID uint32
+	// UpdateKeyRetries is the number of times the kvstore writes of
+	// UpdateKey are retried when they fail transiently, e.g. during a
+	// kvstore load spike. Zero uses the default of defaultUpdateKeyRetries
+	// retries, a negative value disables the retries.
+	UpdateKeyRetries int
+	// UpdateKeyRetryBaseDelay is the delay before the first UpdateKey
+	// retry; each further retry doubles it. Zero uses the default of
+	// defaultUpdateKeyRetryBaseDelay.
+	UpdateKeyRetryBaseDelay time.Duration
+	// TombstoneTTL, if non-zero, makes DeleteIDIfUnused soft-delete master
+	// keys: instead of deleting the key immediately, a tombstone marker
+	// with the deletion timestamp is written, and the key is only deleted
+	// once it is still unused after the TTL elapsed (by a later
+	// DeleteIDIfUnused call or by RunGC). This reduces the window in which
+	// an ID is deleted and immediately re-allocated to a different key
+	// while a concurrent node still references the old mapping. Zero keeps
+	// the default of immediate hard deletion.
+	TombstoneTTL time.Duration
+}
+
+// clusterScope returns the path segment scoping this backend to a cluster, or
+// the empty string if the backend is not cluster scoped.
+func (c KVStoreBackendConfiguration) clusterScope() string {
+	if c.ClusterName != "" {
+		return c.ClusterName
+	}
+	if c.ClusterID != 0 {
+		return strconv.FormatUint(uint64(c.ClusterID), 10)
+	}
+	return ""
+}
+
 // NewKVStoreBackend creates a pkg/allocator.Backend compatible instance. The
 // specific kvstore used is configured in pkg/kvstore.
-func NewKVStoreBackend(basePath, suffix string, typ allocator.AllocatorKey, backend kvstore.BackendOperations) (*kvstoreBackend, error) {
-	if backend == nil {
+func NewKVStoreBackend(c KVStoreBackendConfiguration) (*kvstoreBackend, error) {
+	// An empty suffix would collide the slave keys with the value prefix
+	// itself, reject it for backends that create slave keys.
+	if c.Typ != nil && c.Suffix == "" {
+		return nil, fmt.Errorf("node suffix not configured")
+	}
+
+	if c.SuffixValidator != nil {
+		if err := c.SuffixValidator(c.Suffix); err != nil {
+			return nil, fmt.Errorf("invalid node suffix %q: %w", c.Suffix, err)
+		}
+	}
+
+	if c.Backend == nil {
 		return nil, fmt.Errorf("kvstore client not configured")
 	}
 
+	if c.GCDeleteJitterFraction < 0 || c.GCDeleteJitterFraction > 1 {
+		return nil, fmt.Errorf("GC delete jitter fraction %f out of range [0, 1]", c.GCDeleteJitterFraction)
+	}
+
+	if c.LockTTL < 0 {
+		return nil, fmt.Errorf("negative lock TTL %s", c.LockTTL)
+	}
+
+	if strings.Contains(c.ClusterName, "/") {
+		return nil, fmt.Errorf("invalid cluster name %q: must not contain '/'", c.ClusterName)
+	}
+
+	if c.UpdateKeyRetryBaseDelay < 0 {
+		return nil, fmt.Errorf("negative UpdateKey retry delay %s", c.UpdateKeyRetryBaseDelay)
+	}
+
+	if c.TombstoneTTL < 0 {
+		return nil, fmt.Errorf("negative tombstone TTL %s", c.TombstoneTTL)
+	}
+
+	updateKeyRetries := c.UpdateKeyRetries
+	switch {
+	case updateKeyRetries == 0:
+		updateKeyRetries = defaultUpdateKeyRetries
+	case updateKeyRetries < 0:
+		updateKeyRetries = 0
+	}
+	updateKeyRetryBaseDelay := c.UpdateKeyRetryBaseDelay
+	if updateKeyRetryBaseDelay == 0 {
+		updateKeyRetryBaseDelay = defaultUpdateKeyRetryBaseDelay
+	}
+
+	idEncoder := c.IDEncoder
+	if idEncoder == nil {
+		idEncoder = base10IDEncoder{}
+	}
+
+	// Cluster scoped backends nest all prefixes below a per-cluster
+	// segment. keyToID is unaffected since it decodes master keys relative
+	// to the derived idPrefix.
+	basePath := c.BasePath
+	if scope := c.clusterScope(); scope != "" {
+		basePath = path.Join(basePath, "clusters", scope)
+	}
+
 	return &kvstoreBackend{
-		basePrefix:  basePath,
-		idPrefix:    path.Join(basePath, "id"),
-		valuePrefix: path.Join(basePath, "value"),
-		lockPrefix:  path.Join(basePath, "locks"),
-		suffix:      suffix,
-		keyType:     typ,
-		backend:     backend,
+		basePrefix:              basePath,
+		idPrefix:                path.Join(basePath, "id"),
+		valuePrefix:             path.Join(basePath, "value"),
+		lockPrefix:              path.Join(basePath, "locks"),
+		tombstonePrefix:         path.Join(basePath, "tombstones"),
+		suffix:                  c.Suffix,
+		keyType:                 c.Typ,
+		backend:                 c.Backend,
+		gcDeleteJitterFraction:  c.GCDeleteJitterFraction,
+		idEncoder:               idEncoder,
+		lockTTL:                 c.LockTTL,
+		updateKeyRetries:        updateKeyRetries,
+		updateKeyRetryBaseDelay: updateKeyRetryBaseDelay,
+		tombstoneTTL:            c.TombstoneTTL,
 	}, nil
 }
 
+// idPath returns the master key path for the given ID.
+func (k *kvstoreBackend) idPath(id idpool.ID) string {
+	return path.Join(k.idPrefix, k.idEncoder.EncodeID(id))
+}
+
 // lockPath locks a key in the scope of an allocator
 func (k *kvstoreBackend) lockPath(ctx context.Context, key string) (*kvstore.Lock, error) {
 	suffix := strings.TrimPrefix(key, k.basePrefix)
-	return kvstore.LockPath(ctx, k.backend, path.Join(k.lockPrefix, suffix))
+	return kvstore.LockPathWithTTL(ctx, k.backend, path.Join(k.lockPrefix, suffix), k.lockTTL)
 }
 
-// DeleteAllKeys will delete all keys
-func (k *kvstoreBackend) DeleteAllKeys(ctx context.Context) {
-	k.backend.DeletePrefix(ctx, k.basePrefix)
+// DeleteAllKeys will delete all keys. It returns the number of keys that were
+// present before the wipe (best effort, counted via a prefix listing) and any
+// error returned by the backend.
+func (k *kvstoreBackend) DeleteAllKeys(ctx context.Context) (int, error) {
+	deleted := 0
+	if pairs, err := k.backend.ListPrefix(ctx, k.basePrefix); err != nil {
+		// The count is informational only, proceed with the wipe.
+		log.WithError(err).WithField("prefix", k.basePrefix).Warn("Unable to count keys before deletion")
+	} else {
+		deleted = len(pairs)
+	}
+
+	if err := k.backend.DeletePrefix(ctx, k.basePrefix); err != nil {
+		return 0, err
+	}
+	return deleted, nil
 }
 
 // AllocateID allocates a key->ID mapping in the kvstore.
 func (k *kvstoreBackend) AllocateID(ctx context.Context, id idpool.ID, key allocator.AllocatorKey) (allocator.AllocatorKey, error) {
 	// create /id/<ID> and fail if it already exists
-	keyPath := path.Join(k.idPrefix, id.String())
+	keyPath := k.idPath(id)
 	keyEncoded := []byte(k.backend.Encode([]byte(key.GetKey())))
 	success, err := k.backend.CreateOnly(ctx, keyPath, keyEncoded, false)
 	if err != nil || !success {
@@ -128,7 +383,7 @@ func (k *kvstoreBackend) AllocateID(ctx context.Context, id idpool.ID, key alloc
 // AllocateID allocates a key->ID mapping in the kvstore.
 func (k *kvstoreBackend) AllocateIDIfLocked(ctx context.Context, id idpool.ID, key allocator.AllocatorKey, lock kvstore.KVLocker) (allocator.AllocatorKey, error) {
 	// create /id/<ID> and fail if it already exists
-	keyPath := path.Join(k.idPrefix, id.String())
+	keyPath := k.idPath(id)
 	keyEncoded := []byte(k.backend.Encode([]byte(key.GetKey())))
 	success, err := k.backend.CreateOnlyIfLocked(ctx, keyPath, keyEncoded, false, lock)
 	if err != nil || !success {
@@ -162,7 +417,7 @@ func (k *kvstoreBackend) createValueNodeKey(ctx context.Context, key string, new
 // Lock locks a key in the scope of an allocator
 func (k *kvstoreBackend) lock(ctx context.Context, key string) (*kvstore.Lock, error) {
 	suffix := strings.TrimPrefix(key, k.basePrefix)
-	return kvstore.LockPath(ctx, k.backend, path.Join(k.lockPrefix, suffix))
+	return kvstore.LockPathWithTTL(ctx, k.backend, path.Join(k.lockPrefix, suffix), k.lockTTL)
 }
 
 // Lock locks a key in the scope of an allocator
@@ -243,10 +498,154 @@ func (k *kvstoreBackend) GetIfLocked(ctx context.Context, key allocator.Allocato
 	return idpool.NoID, nil
 }
 
+// ListUsers returns the node suffixes that still hold a slave key, i.e. a
+// reference, for the given ID. It resolves the master key to obtain the key
+// string and lists the slave keys below it, which tells an operator which
+// nodes are preventing an identity from being garbage collected.
+func (k *kvstoreBackend) ListUsers(ctx context.Context, id idpool.ID) ([]string, error) {
+	v, err := k.backend.Get(ctx, k.idPath(id))
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("no master key found for ID %s", id)
+	}
+
+	// Slave keys are stored below the encoded key string, so the master
+	// key's value can be used as prefix as-is.
+	prefix := path.Join(k.valuePrefix, string(v))
+	pairs, err := k.backend.ListPrefix(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]string, 0, len(pairs))
+	for key := range pairs {
+		// Skip longer keys sharing the same prefix, see Get().
+		if !prefixMatchesKey(prefix, key) {
+			continue
+		}
+		users = append(users, key[strings.LastIndex(key, "/")+1:])
+	}
+	sort.Strings(users)
+
+	return users, nil
+}
+
+// Inconsistency describes a master/slave key discrepancy found by
+// VerifyConsistency.
+type Inconsistency struct {
+	// ID is the affected identity ID. It is zero for orphaned slave keys
+	// whose value could not be parsed.
+	ID idpool.ID
+
+	// Key is the encoded allocator key string as stored in the kvstore.
+	Key string
+
+	// MissingMaster is true when slave keys reference an ID whose master
+	// key is gone, and false when a master key has no slave keys left.
+	MissingMaster bool
+
+	// Repaired is true when the missing master key has been re-created.
+	Repaired bool
+}
+
+// VerifyConsistency performs a bulk verification pass over the master and
+// slave keys: it reports master keys without a single remaining slave key
+// (candidates for the next GC rounds) and slave keys whose master key is gone
+// (identities at risk of being re-allocated with a different ID). When repair
+// is set, missing master keys are re-created from the orphaned slave keys,
+// mirroring what UpdateKey does for the local node's own keys.
+func (k *kvstoreBackend) VerifyConsistency(ctx context.Context, repair bool) ([]Inconsistency, error) {
+	masters, err := k.backend.ListPrefix(ctx, k.idPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("list master keys failed: %w", err)
+	}
+	slaves, err := k.backend.ListPrefix(ctx, k.valuePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("list slave keys failed: %w", err)
+	}
+
+	// Index the slave keys by their encoded allocator key and by the ID
+	// they reference.
+	slaveKeys := map[string]struct{}{}
+	slaveIDs := map[string]string{}
+	for key, v := range slaves {
+		if !strings.HasPrefix(key, k.valuePrefix+"/") {
+			continue
+		}
+		suffix := key[len(k.valuePrefix)+1:]
+		lastSlash := strings.LastIndex(suffix, "/")
+		if lastSlash < 0 {
+			continue
+		}
+		encodedKey := suffix[:lastSlash]
+		slaveKeys[encodedKey] = struct{}{}
+		slaveIDs[string(v.Data)] = encodedKey
+	}
+
+	var inconsistencies []Inconsistency
+
+	// Master keys without any remaining slave key.
+	for key, v := range masters {
+		id, err := k.keyToID(key)
+		if err != nil {
+			continue
+		}
+		if _, ok := slaveKeys[string(v.Data)]; !ok {
+			inconsistencies = append(inconsistencies, Inconsistency{
+				ID:  id,
+				Key: string(v.Data),
+			})
+		}
+	}
+
+	// Slave keys referencing an ID whose master key is gone. Slave key
+	// values always carry the plain base-10 ID, so re-encode it to obtain
+	// the master key path.
+	for idStr, encodedKey := range slaveIDs {
+		inc := Inconsistency{
+			Key:           encodedKey,
+			MissingMaster: true,
+		}
+		masterPath := path.Join(k.idPrefix, idStr)
+		if id, err := strconv.ParseUint(idStr, 10, 64); err == nil {
+			inc.ID = idpool.ID(id)
+			masterPath = k.idPath(inc.ID)
+		}
+		if _, ok := masters[masterPath]; ok {
+			continue
+		}
+		if repair {
+			success, err := k.backend.CreateOnly(ctx, masterPath, []byte(encodedKey), false)
+			if err != nil {
+				return inconsistencies, fmt.Errorf("unable to re-create master key for ID %s: %w", idStr, err)
+			}
+			if success {
+				log.WithFields(logrus.Fields{
+					logfields.Key:      encodedKey,
+					logfields.Identity: idStr,
+				}).Warning("Re-created missing master key for orphaned slave keys")
+				inc.Repaired = true
+			}
+		}
+		inconsistencies = append(inconsistencies, inc)
+	}
+
+	sort.Slice(inconsistencies, func(i, j int) bool {
+		if inconsistencies[i].ID != inconsistencies[j].ID {
+			return inconsistencies[i].ID < inconsistencies[j].ID
+		}
+		return inconsistencies[i].Key < inconsistencies[j].Key
+	})
+
+	return inconsistencies, nil
+}
+
 // GetByID returns the key associated with an ID. Returns nil if no key is
 // associated with the ID.
 func (k *kvstoreBackend) GetByID(ctx context.Context, id idpool.ID) (allocator.AllocatorKey, error) {
-	v, err := k.backend.Get(ctx, path.Join(k.idPrefix, id.String()))
+	v, err := k.backend.Get(ctx, k.idPath(id))
 	if err != nil {
 		return nil, err
 	}
@@ -263,21 +662,54 @@ func (k *kvstoreBackend) GetByID(ctx context.Context, id idpool.ID) (allocator.A
 	return k.keyType.PutKey(string(s)), nil
 }
 
+// isTransientKVStoreError distinguishes the kvstore errors worth retrying
+// from the ones a retry cannot recover. Conflicts with existing keys are
+// reported through the boolean result of CreateOnly/UpdateIfDifferent and
+// never produce an error, so any error other than an expired context or lock
+// lease is considered transient.
+func isTransientKVStoreError(err error) bool {
+	return !errors.Is(err, context.Canceled) &&
+		!errors.Is(err, context.DeadlineExceeded) &&
+		!errors.Is(err, kvstore.ErrLockLeaseExpired)
+}
+
+// retryUpdateKeyOp invokes op, retrying it with exponential backoff when it
+// fails with a transient error, up to updateKeyRetries retries. The result of
+// the last attempt is returned.
+func (k *kvstoreBackend) retryUpdateKeyOp(ctx context.Context, op func() (bool, error)) (bool, error) {
+	delay := k.updateKeyRetryBaseDelay
+	for attempt := 0; ; attempt++ {
+		ok, err := op()
+		if err == nil || attempt >= k.updateKeyRetries || !isTransientKVStoreError(err) {
+			return ok, err
+		}
+		select {
+		case <-ctx.Done():
+			return ok, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
 // UpdateKey refreshes the record that this node is using this key -> id
 // mapping. When reliablyMissing is set it will also recreate missing master or
-// slave keys.
+// slave keys. The kvstore writes are retried with backoff when they fail
+// transiently, see KVStoreBackendConfiguration.UpdateKeyRetries.
 func (k *kvstoreBackend) UpdateKey(ctx context.Context, id idpool.ID, key allocator.AllocatorKey, reliablyMissing bool) error {
 	var (
 		err        error
 		recreated  bool
-		keyPath    = path.Join(k.idPrefix, id.String())
+		keyPath    = k.idPath(id)
 		keyEncoded = []byte(k.backend.Encode([]byte(key.GetKey())))
 		valueKey   = path.Join(k.valuePrefix, k.backend.Encode([]byte(key.GetKey())), k.suffix)
 	)
 
 	// Use of CreateOnly() ensures that any existing potentially
 	// conflicting key is never overwritten.
-	success, err := k.backend.CreateOnly(ctx, keyPath, keyEncoded, false)
+	success, err := k.retryUpdateKeyOp(ctx, func() (bool, error) {
+		return k.backend.CreateOnly(ctx, keyPath, keyEncoded, false)
+	})
 	switch {
 	case err != nil:
 		return fmt.Errorf("Unable to re-create missing master key \"%s\" -> \"%s\": %w", logfields.Key, valueKey, err)
@@ -288,11 +720,12 @@ func (k *kvstoreBackend) UpdateKey(ctx context.Context, id idpool.ID, key alloca
 	// Also re-create the slave key in case it has been deleted. This will
 	// ensure that the next garbage collection cycle of any participating
 	// node does not remove the master key again.
-	if reliablyMissing {
-		recreated, err = k.backend.CreateOnly(ctx, valueKey, []byte(id.String()), true)
-	} else {
-		recreated, err = k.backend.UpdateIfDifferent(ctx, valueKey, []byte(id.String()), true)
-	}
+	recreated, err = k.retryUpdateKeyOp(ctx, func() (bool, error) {
+		if reliablyMissing {
+			return k.backend.CreateOnly(ctx, valueKey, []byte(id.String()), true)
+		}
+		return k.backend.UpdateIfDifferent(ctx, valueKey, []byte(id.String()), true)
+	})
 	switch {
 	case err != nil:
 		return fmt.Errorf("Unable to re-create missing slave key \"%s\" -> \"%s\": %w", logfields.Key, valueKey, err)
@@ -310,14 +743,16 @@ func (k *kvstoreBackend) UpdateKeyIfLocked(ctx context.Context, id idpool.ID, ke
 	var (
 		err        error
 		recreated  bool
-		keyPath    = path.Join(k.idPrefix, id.String())
+		keyPath    = k.idPath(id)
 		keyEncoded = []byte(k.backend.Encode([]byte(key.GetKey())))
 		valueKey   = path.Join(k.valuePrefix, k.backend.Encode([]byte(key.GetKey())), k.suffix)
 	)
 
 	// Use of CreateOnly() ensures that any existing potentially
 	// conflicting key is never overwritten.
-	success, err := k.backend.CreateOnlyIfLocked(ctx, keyPath, keyEncoded, false, lock)
+	success, err := k.retryUpdateKeyOp(ctx, func() (bool, error) {
+		return k.backend.CreateOnlyIfLocked(ctx, keyPath, keyEncoded, false, lock)
+	})
 	switch {
 	case err != nil:
 		return fmt.Errorf("Unable to re-create missing master key \"%s\" -> \"%s\": %w", logfields.Key, valueKey, err)
@@ -329,11 +764,12 @@ func (k *kvstoreBackend) UpdateKeyIfLocked(ctx context.Context, id idpool.ID, ke
 	// ensure that the next garbage collection cycle of any participating
 	// node does not remove the master key again.
 	// lock is ignored since the key doesn't exist.
-	if reliablyMissing {
-		recreated, err = k.backend.CreateOnly(ctx, valueKey, []byte(id.String()), true)
-	} else {
-		recreated, err = k.backend.UpdateIfDifferentIfLocked(ctx, valueKey, []byte(id.String()), true, lock)
-	}
+	recreated, err = k.retryUpdateKeyOp(ctx, func() (bool, error) {
+		if reliablyMissing {
+			return k.backend.CreateOnly(ctx, valueKey, []byte(id.String()), true)
+		}
+		return k.backend.UpdateIfDifferentIfLocked(ctx, valueKey, []byte(id.String()), true, lock)
+	})
 	switch {
 	case err != nil:
 		return fmt.Errorf("Unable to re-create missing slave key \"%s\" -> \"%s\": %w", logfields.Key, valueKey, err)
@@ -367,6 +803,100 @@ func (k *kvstoreBackend) Release(ctx context.Context, _ idpool.ID, key allocator
 	return nil
 }
 
+// tombstonePathFor returns the tombstone marker key for the given master key.
+func (k *kvstoreBackend) tombstonePathFor(masterKey string) string {
+	return path.Join(k.tombstonePrefix, path.Base(masterKey))
+}
+
+// tombstoneExpired reports whether the deletion timestamp stored in a
+// tombstone marker lies further than the configured TTL in the past. A marker
+// which cannot be parsed counts as expired so that a corrupt marker cannot
+// block deletion forever.
+func (k *kvstoreBackend) tombstoneExpired(value []byte) bool {
+	t, err := time.Parse(time.RFC3339Nano, string(value))
+	if err != nil {
+		return true
+	}
+	return time.Since(t) > k.tombstoneTTL
+}
+
+// DeleteIDIfUnused deletes the master key of the given ID if no slave key
+// references it anymore. It takes the same per-key lock as RunGC and reports
+// whether the master key was deleted, giving direct release paths a safe
+// deletion primitive which cannot orphan slave keys still using the ID.
+//
+// With a non-zero TombstoneTTL the deletion is a two-phase soft delete: the
+// first call on an unused key only writes a tombstone marker and leaves the
+// master key in place, and the key is deleted once it is still unused after
+// the TTL elapsed, either by a later call or by RunGC.
+func (k *kvstoreBackend) DeleteIDIfUnused(ctx context.Context, id idpool.ID) (deleted bool, err error) {
+	key := k.idPath(id)
+
+	lock, err := k.lockPath(ctx, key)
+	if err != nil {
+		return false, fmt.Errorf("unable to lock key %s: %w", key, err)
+	}
+	defer lock.Unlock(context.Background())
+
+	v, err := k.backend.GetIfLocked(ctx, key, lock)
+	if err != nil {
+		return false, err
+	}
+	if v == nil {
+		// The master key is already gone, nothing to delete.
+		return false, nil
+	}
+
+	// fetch list of all /value/<key> keys
+	valueKeyPrefix := path.Join(k.valuePrefix, string(v))
+	pairs, err := k.backend.ListPrefixIfLocked(ctx, valueKeyPrefix, lock)
+	if err != nil {
+		return false, fmt.Errorf("unable to list slave keys below %s: %w", valueKeyPrefix, err)
+	}
+	for prefix := range pairs {
+		if prefixMatchesKey(valueKeyPrefix, prefix) {
+			// The ID is still in use, leave the master key in place. Clear
+			// a possibly pending tombstone so that the renewed use resets
+			// the deletion clock.
+			if k.tombstoneTTL > 0 {
+				if err := k.backend.DeleteIfLocked(ctx, k.tombstonePathFor(key), lock); err != nil {
+					log.WithError(err).WithField(logfields.Key, key).Warning("Unable to clear stale tombstone marker")
+				}
+			}
+			return false, nil
+		}
+	}
+
+	if k.tombstoneTTL > 0 {
+		tombstoneKey := k.tombstonePathFor(key)
+		marker, err := k.backend.GetIfLocked(ctx, tombstoneKey, lock)
+		if err != nil {
+			return false, fmt.Errorf("unable to read tombstone marker %s: %w", tombstoneKey, err)
+		}
+		if marker == nil {
+			// First unused observation, only mark the key for deletion.
+			stamp := []byte(time.Now().UTC().Format(time.RFC3339Nano))
+			if _, err := k.backend.UpdateIfDifferentIfLocked(ctx, tombstoneKey, stamp, false, lock); err != nil {
+				return false, fmt.Errorf("unable to write tombstone marker %s: %w", tombstoneKey, err)
+			}
+			return false, nil
+		}
+		if !k.tombstoneExpired(marker) {
+			// Still within the grace period, leave the master key in place.
+			return false, nil
+		}
+		if err := k.backend.DeleteIfLocked(ctx, tombstoneKey, lock); err != nil {
+			return false, fmt.Errorf("unable to delete tombstone marker %s: %w", tombstoneKey, err)
+		}
+	}
+
+	if err := k.backend.DeleteIfLocked(ctx, key, lock); err != nil {
+		return false, fmt.Errorf("unable to delete master key %s: %w", key, err)
+	}
+
+	return true, nil
+}
+
 // RunLocksGC scans the kvstore for unused locks and removes them. Returns
 // a map of locks that are currently being held, including the ones that have
 // failed to be GCed.
@@ -381,6 +911,11 @@ func (k *kvstoreBackend) RunLocksGC(ctx context.Context, staleKeysPrevRound map[
 
 	// iterate over /../locks
 	for key, v := range allocated {
+		// Return early on shutdown, keeping the stale candidates collected so
+		// far for the next round.
+		if err := ctx.Err(); err != nil {
+			return staleKeys, err
+		}
 		scopedLog := log.WithFields(logrus.Fields{
 			logfields.Key:     key,
 			logfields.LeaseID: strconv.FormatUint(uint64(v.LeaseID), 16),
@@ -428,6 +963,7 @@ func (k *kvstoreBackend) RunGC(
 
 	totalEntries := len(allocated)
 	deletedEntries := 0
+	lockFailures := 0
 
 	staleKeys := map[string]uint64{}
 
@@ -464,6 +1000,7 @@ func (k *kvstoreBackend) RunGC(
 
 		lock, err := k.lockPath(ctx, key)
 		if err != nil {
+			lockFailures++
 			log.WithError(err).WithField(logfields.Key, key).Warning("allocator garbage collector was unable to lock key")
 			continue
 		}
@@ -492,6 +1029,39 @@ func (k *kvstoreBackend) RunGC(
 				logfields.Key:      key,
 				logfields.Identity: path.Base(key),
 			})
+			if k.tombstoneTTL > 0 {
+				// In tombstone mode a pending tombstone takes the place of
+				// the two-round staleness marking: the key is deleted once
+				// its tombstone expired and is left alone until then.
+				tombstoneKey := k.tombstonePathFor(key)
+				if marker, err := k.backend.GetIfLocked(ctx, tombstoneKey, lock); err != nil {
+					scopedLog.WithError(err).Warning("Unable to read tombstone marker")
+					lock.Unlock(context.Background())
+					continue
+				} else if marker != nil {
+					if k.tombstoneExpired(marker) {
+						if err := k.backend.DeleteIfLocked(ctx, key, lock); err != nil {
+							scopedLog.WithError(err).Warning("Unable to delete tombstoned allocator master key")
+						} else if err := k.backend.DeleteIfLocked(ctx, tombstoneKey, lock); err != nil {
+							scopedLog.WithError(err).Warning("Unable to delete expired tombstone marker")
+						} else {
+							deletedEntries++
+							scopedLog.Info("Deleted tombstoned allocator master key")
+						}
+						// consider the key regardless if there was an error
+						// from the kvstore, to rate limit the number of
+						// requests done to the KVStore.
+						deleted = true
+					}
+					lock.Unlock(context.Background())
+					if deleted {
+						if err := k.gcDeleteWait(ctx, rateLimit); err != nil {
+							return nil, nil, err
+						}
+					}
+					continue
+				}
+			}
 			// Only delete if this key was previously marked as to be deleted
 			if modRev, ok := staleKeysPrevRound[key]; ok {
 				// if the v.ModRevision is different than the modRev (which is
@@ -522,7 +1092,7 @@ func (k *kvstoreBackend) RunGC(
 			// done the operation that should be rate limited before checking the
 			// rate limit. We have to do this here to avoid holding the global lock
 			// for a long period of time.
-			err = rateLimit.Wait(ctx)
+			err = k.gcDeleteWait(ctx, rateLimit)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -530,12 +1100,44 @@ func (k *kvstoreBackend) RunGC(
 	}
 
 	gcStats := &allocator.GCStats{
-		Alive:   totalEntries - deletedEntries,
-		Deleted: deletedEntries,
+		Alive:        totalEntries - deletedEntries,
+		Deleted:      deletedEntries,
+		LockFailures: lockFailures,
 	}
 	return staleKeys, gcStats, nil
 }
 
+// gcDeleteWait applies the rate limit between RunGC deletes. When a jitter
+// fraction is configured, a random extra delay proportional to the time the
+// rate limiter blocked is layered on top, so that the delete cadence of many
+// agents running GC with the same configuration desynchronizes instead of
+// producing aligned kvstore load spikes.
+func (k *kvstoreBackend) gcDeleteWait(ctx context.Context, rateLimit *rate.Limiter) error {
+	start := time.Now()
+	if err := rateLimit.Wait(ctx); err != nil {
+		return err
+	}
+
+	jitter := gcDeleteJitter(time.Since(start), k.gcDeleteJitterFraction)
+	if jitter <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(jitter):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// gcDeleteJitter returns a random duration in [0, fraction*base].
+func gcDeleteJitter(base time.Duration, fraction float64) time.Duration {
+	if base <= 0 || fraction <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Float64() * fraction * float64(base))
+}
+
 func (k *kvstoreBackend) keyToID(key string) (id idpool.ID, err error) {
 	if !strings.HasPrefix(key, k.idPrefix) {
 		return idpool.NoID, fmt.Errorf("Found invalid key \"%s\" outside of prefix \"%s\"", key, k.idPrefix)
@@ -546,17 +1148,19 @@ func (k *kvstoreBackend) keyToID(key string) (id idpool.ID, err error) {
 		suffix = suffix[1:]
 	}
 
-	idParsed, err := strconv.ParseUint(suffix, 10, 64)
+	idParsed, err := k.idEncoder.DecodeID(suffix)
 	if err != nil {
 		return idpool.NoID, fmt.Errorf("Cannot parse key suffix \"%s\"", suffix)
 	}
 
-	return idpool.ID(idParsed), nil
+	return idParsed, nil
 }
 
 func (k *kvstoreBackend) ListAndWatch(ctx context.Context, handler allocator.CacheMutations, stopChan chan struct{}) {
 	watcher := k.backend.ListAndWatch(ctx, k.idPrefix, 512)
 
+	initialListDone := false
+
 	for {
 		select {
 		case event, ok := <-watcher.Events:
@@ -564,7 +1168,17 @@ func (k *kvstoreBackend) ListAndWatch(ctx context.Context, handler allocator.Cac
 				goto abort
 			}
 			if event.Typ == kvstore.EventTypeListDone {
-				handler.OnListDone()
+				// Only the first list-done completes the initial
+				// sync. Later ones mean the watch restarted its
+				// list phase and replayed a fresh snapshot, which
+				// is surfaced as a resync to handlers interested
+				// in reconciling against it.
+				if !initialListDone {
+					initialListDone = true
+					handler.OnListDone()
+				} else if resyncHandler, ok := handler.(allocator.CacheMutationsResync); ok {
+					resyncHandler.OnResync()
+				}
 				continue
 			}
 
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"math"
 	"path"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -77,7 +78,7 @@ func BenchmarkAllocate(b *testing.B) {
 func benchmarkAllocate(b *testing.B) {
 	allocatorName := randomTestName()
 	maxID := idpool.ID(256 + b.N)
-	backend, err := NewKVStoreBackend(allocatorName, "a", TestAllocatorKey(""), kvstore.Client())
+	backend, err := NewKVStoreBackend(KVStoreBackendConfiguration{BasePath: allocatorName, Suffix: "a", Typ: TestAllocatorKey(""), Backend: kvstore.Client()})
 	require.NoError(b, err)
 	a, err := allocator.NewAllocator(TestAllocatorKey(""), backend, allocator.WithMax(maxID))
 	require.NoError(b, err)
@@ -106,7 +107,7 @@ func benchmarkRunLocksGC(b *testing.B, backendName string) {
 	allocatorName := randomTestName()
 	maxID := idpool.ID(256 + b.N)
 	// FIXME: Did this previously use allocatorName := randomTestName() ? so TestAllocatorKey(randomeTestName())
-	backend1, err := NewKVStoreBackend(allocatorName, "a", TestAllocatorKey(""), kvstore.Client())
+	backend1, err := NewKVStoreBackend(KVStoreBackendConfiguration{BasePath: allocatorName, Suffix: "a", Typ: TestAllocatorKey(""), Backend: kvstore.Client()})
 	require.NoError(b, err)
 	allocator, err := allocator.NewAllocator(TestAllocatorKey(""), backend1, allocator.WithMax(maxID), allocator.WithoutGC())
 	require.NoError(b, err)
@@ -248,7 +249,7 @@ func benchmarkGC(b *testing.B) {
 	allocatorName := randomTestName()
 	maxID := idpool.ID(256 + b.N)
 	// FIXME: Did this previously use allocatorName := randomTestName() ? so TestAllocatorKey(randomeTestName())
-	backend, err := NewKVStoreBackend(allocatorName, "a", TestAllocatorKey(""), kvstore.Client())
+	backend, err := NewKVStoreBackend(KVStoreBackendConfiguration{BasePath: allocatorName, Suffix: "a", Typ: TestAllocatorKey(""), Backend: kvstore.Client()})
 	require.NoError(b, err)
 	allocator, err := allocator.NewAllocator(TestAllocatorKey(""), backend, allocator.WithMax(maxID), allocator.WithoutGC())
 	require.NoError(b, err)
@@ -305,7 +306,7 @@ func BenchmarkGCShouldSkipOutOfRangeIdentities(b *testing.B) {
 
 func benchmarkGCShouldSkipOutOfRangeIdentities(b *testing.B) {
 	// Allocator1: allocator under test
-	backend, err := NewKVStoreBackend(randomTestName(), "a", TestAllocatorKey(""), kvstore.Client())
+	backend, err := NewKVStoreBackend(KVStoreBackendConfiguration{BasePath: randomTestName(), Suffix: "a", Typ: TestAllocatorKey(""), Backend: kvstore.Client()})
 	require.NoError(b, err)
 
 	maxID1 := idpool.ID(4 + b.N)
@@ -327,7 +328,7 @@ func benchmarkGCShouldSkipOutOfRangeIdentities(b *testing.B) {
 	require.NoError(b, err)
 
 	// Alloctor2: with a non-overlapping range compared with allocator1
-	backend2, err := NewKVStoreBackend(randomTestName(), "a", TestAllocatorKey(""), kvstore.Client())
+	backend2, err := NewKVStoreBackend(KVStoreBackendConfiguration{BasePath: randomTestName(), Suffix: "a", Typ: TestAllocatorKey(""), Backend: kvstore.Client()})
 	require.NoError(b, err)
 
 	minID2 := maxID1 + 1
@@ -387,7 +388,7 @@ func TestAllocateCached(t *testing.T) {
 }
 
 func testAllocatorCached(t *testing.T, maxID idpool.ID, allocatorName string) {
-	backend, err := NewKVStoreBackend(allocatorName, "a", TestAllocatorKey(""), kvstore.Client())
+	backend, err := NewKVStoreBackend(KVStoreBackendConfiguration{BasePath: allocatorName, Suffix: "a", Typ: TestAllocatorKey(""), Backend: kvstore.Client()})
 	require.NoError(t, err)
 	a, err := allocator.NewAllocator(TestAllocatorKey(""), backend,
 		allocator.WithMax(maxID), allocator.WithoutGC())
@@ -418,7 +419,7 @@ func testAllocatorCached(t *testing.T, maxID idpool.ID, allocatorName string) {
 	}
 
 	// Create a 2nd allocator, refill it
-	backend2, err := NewKVStoreBackend(allocatorName, "r", TestAllocatorKey(""), kvstore.Client())
+	backend2, err := NewKVStoreBackend(KVStoreBackendConfiguration{BasePath: allocatorName, Suffix: "r", Typ: TestAllocatorKey(""), Backend: kvstore.Client()})
 	require.NoError(t, err)
 	a2, err := allocator.NewAllocator(TestAllocatorKey(""), backend2,
 		allocator.WithMax(maxID), allocator.WithoutGC())
@@ -486,7 +487,7 @@ func TestKeyToID(t *testing.T) {
 
 func testKeyToID(t *testing.T) {
 	allocatorName := randomTestName()
-	backend, err := NewKVStoreBackend(allocatorName, "a", TestAllocatorKey(""), kvstore.Client())
+	backend, err := NewKVStoreBackend(KVStoreBackendConfiguration{BasePath: allocatorName, Suffix: "a", Typ: TestAllocatorKey(""), Backend: kvstore.Client()})
 	require.NoError(t, err)
 	a, err := allocator.NewAllocator(TestAllocatorKey(""), backend)
 	require.NoError(t, err)
@@ -521,7 +522,7 @@ func TestGetNoCache(t *testing.T) {
 
 func testGetNoCache(t *testing.T, maxID idpool.ID) {
 	allocatorName := randomTestName()
-	backend, err := NewKVStoreBackend(allocatorName, "a", TestAllocatorKey(""), kvstore.Client())
+	backend, err := NewKVStoreBackend(KVStoreBackendConfiguration{BasePath: allocatorName, Suffix: "a", Typ: TestAllocatorKey(""), Backend: kvstore.Client()})
 	require.NoError(t, err)
 	allocator, err := allocator.NewAllocator(TestAllocatorKey(""), backend, allocator.WithMax(maxID), allocator.WithoutGC())
 	require.NoError(t, err)
@@ -609,7 +610,7 @@ func TestRemoteCache(t *testing.T) {
 
 func testRemoteCache(t *testing.T) {
 	testName := randomTestName()
-	backend, err := NewKVStoreBackend(testName, "a", TestAllocatorKey(""), kvstore.Client())
+	backend, err := NewKVStoreBackend(KVStoreBackendConfiguration{BasePath: testName, Suffix: "a", Typ: TestAllocatorKey(""), Backend: kvstore.Client()})
 	require.NoError(t, err)
 	a, err := allocator.NewAllocator(TestAllocatorKey(""), backend, allocator.WithMax(idpool.ID(256)))
 	require.NoError(t, err)
@@ -652,7 +653,7 @@ func testRemoteCache(t *testing.T) {
 	}
 
 	// watch the prefix in the same kvstore via a 2nd watcher
-	backend2, err := NewKVStoreBackend(testName, "a", TestAllocatorKey(""), kvstore.Client())
+	backend2, err := NewKVStoreBackend(KVStoreBackendConfiguration{BasePath: testName, Suffix: "a", Typ: TestAllocatorKey(""), Backend: kvstore.Client()})
 	require.NoError(t, err)
 	a2, err := allocator.NewAllocator(TestAllocatorKey(""), backend2, allocator.WithMax(idpool.ID(256)),
 		allocator.WithoutAutostart(), allocator.WithoutGC())
@@ -707,3 +708,402 @@ func opts(backendName string) map[string]string {
 
 	return nil
 }
+
+func TestKVStoreBackendSuffixValidation(t *testing.T) {
+	// An empty suffix must be rejected for backends that create slave keys.
+	_, err := NewKVStoreBackend(KVStoreBackendConfiguration{
+		BasePath: testPrefix,
+		Suffix:   "",
+		Typ:      TestAllocatorKey(""),
+	})
+	require.ErrorContains(t, err, "node suffix not configured")
+
+	// A custom validator can enforce a deployment-specific suffix format.
+	_, err = NewKVStoreBackend(KVStoreBackendConfiguration{
+		BasePath: testPrefix,
+		Suffix:   "not-an-ip",
+		Typ:      TestAllocatorKey(""),
+		SuffixValidator: func(suffix string) error {
+			return fmt.Errorf("suffix must be an IP address")
+		},
+	})
+	require.ErrorContains(t, err, "suffix must be an IP address")
+}
+
+func TestKVStoreBackendClusterScoping(t *testing.T) {
+	fake := &fakeLockBackend{}
+
+	// Without cluster scoping the prefixes derive directly from BasePath.
+	backend, err := NewKVStoreBackend(KVStoreBackendConfiguration{
+		BasePath: testPrefix,
+		Suffix:   "node1",
+		Typ:      TestAllocatorKey(""),
+		Backend:  fake,
+	})
+	require.NoError(t, err)
+	require.Equal(t, testPrefix+"/id", backend.idPrefix)
+	require.Equal(t, testPrefix+"/value", backend.valuePrefix)
+	require.Equal(t, testPrefix+"/locks", backend.lockPrefix)
+
+	// A cluster name inserts a scoping segment into all prefixes.
+	backend, err = NewKVStoreBackend(KVStoreBackendConfiguration{
+		BasePath:    testPrefix,
+		Suffix:      "node1",
+		Typ:         TestAllocatorKey(""),
+		Backend:     fake,
+		ClusterName: "remote",
+	})
+	require.NoError(t, err)
+	require.Equal(t, testPrefix+"/clusters/remote/id", backend.idPrefix)
+	require.Equal(t, testPrefix+"/clusters/remote/value", backend.valuePrefix)
+	require.Equal(t, testPrefix+"/clusters/remote/locks", backend.lockPrefix)
+
+	// keyToID decodes master keys relative to the scoped idPrefix.
+	id, err := backend.keyToID(testPrefix + "/clusters/remote/id/1234")
+	require.NoError(t, err)
+	require.Equal(t, idpool.ID(1234), id)
+
+	// The cluster name takes precedence over the cluster ID; the ID alone
+	// scopes by its base-10 representation.
+	backend, err = NewKVStoreBackend(KVStoreBackendConfiguration{
+		BasePath:  testPrefix,
+		Suffix:    "node1",
+		Typ:       TestAllocatorKey(""),
+		Backend:   fake,
+		ClusterID: 3,
+	})
+	require.NoError(t, err)
+	require.Equal(t, testPrefix+"/clusters/3/id", backend.idPrefix)
+
+	// A cluster name containing a path separator would break out of the
+	// scoping segment and must be rejected.
+	_, err = NewKVStoreBackend(KVStoreBackendConfiguration{
+		BasePath:    testPrefix,
+		Suffix:      "node1",
+		Typ:         TestAllocatorKey(""),
+		Backend:     fake,
+		ClusterName: "remote/../other",
+	})
+	require.ErrorContains(t, err, "must not contain '/'")
+}
+
+func TestKVStoreBackendTombstones(t *testing.T) {
+	fake := &fakeLockBackend{}
+
+	// A negative TTL is rejected.
+	_, err := NewKVStoreBackend(KVStoreBackendConfiguration{
+		BasePath:     testPrefix,
+		Suffix:       "node1",
+		Typ:          TestAllocatorKey(""),
+		Backend:      fake,
+		TombstoneTTL: -time.Second,
+	})
+	require.ErrorContains(t, err, "negative tombstone TTL")
+
+	backend, err := NewKVStoreBackend(KVStoreBackendConfiguration{
+		BasePath:     testPrefix,
+		Suffix:       "node1",
+		Typ:          TestAllocatorKey(""),
+		Backend:      fake,
+		TombstoneTTL: time.Minute,
+	})
+	require.NoError(t, err)
+	require.Equal(t, testPrefix+"/tombstones/1234", backend.tombstonePathFor(testPrefix+"/id/1234"))
+
+	// A fresh marker is still within the grace period, one older than the
+	// TTL has expired.
+	fresh := []byte(time.Now().UTC().Format(time.RFC3339Nano))
+	require.False(t, backend.tombstoneExpired(fresh))
+	stale := []byte(time.Now().UTC().Add(-2 * time.Minute).Format(time.RFC3339Nano))
+	require.True(t, backend.tombstoneExpired(stale))
+
+	// A corrupt marker counts as expired so it cannot block deletion.
+	require.True(t, backend.tombstoneExpired([]byte("not-a-timestamp")))
+}
+
+// fakeLockBackend stubs out the kvstore operations used by RunLocksGC. All
+// other BackendOperations calls panic via the embedded nil interface.
+type fakeLockBackend struct {
+	kvstore.BackendOperations
+
+	locks   map[string]kvstore.Value
+	deletes int
+}
+
+func (f *fakeLockBackend) ListPrefix(ctx context.Context, prefix string) (kvstore.KeyValuePairs, error) {
+	pairs := kvstore.KeyValuePairs{}
+	for k, v := range f.locks {
+		pairs[k] = v
+	}
+	return pairs, nil
+}
+
+func (f *fakeLockBackend) Delete(ctx context.Context, key string) error {
+	f.deletes++
+	return nil
+}
+
+func TestRunLocksGCCancellation(t *testing.T) {
+	fake := &fakeLockBackend{
+		locks: map[string]kvstore.Value{
+			"lock1": {ModRevision: 1},
+			"lock2": {ModRevision: 2},
+		},
+	}
+	backend, err := NewKVStoreBackend(KVStoreBackendConfiguration{
+		BasePath: testPrefix,
+		Suffix:   "a",
+		Typ:      TestAllocatorKey(""),
+		Backend:  fake,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// With a cancelled context the GC must bail out before attempting any
+	// deletes, even for locks already marked stale in the previous round.
+	staleKeys, err := backend.RunLocksGC(ctx, fake.locks)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Empty(t, staleKeys)
+	require.Zero(t, fake.deletes)
+}
+
+// fakeUsersBackend stubs out the kvstore operations used by ListUsers.
+type fakeUsersBackend struct {
+	kvstore.BackendOperations
+
+	kvs map[string][]byte
+}
+
+func (f *fakeUsersBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	return f.kvs[key], nil
+}
+
+func (f *fakeUsersBackend) ListPrefix(ctx context.Context, prefix string) (kvstore.KeyValuePairs, error) {
+	pairs := kvstore.KeyValuePairs{}
+	for k, v := range f.kvs {
+		if strings.HasPrefix(k, prefix) {
+			pairs[k] = kvstore.Value{Data: v}
+		}
+	}
+	return pairs, nil
+}
+
+func TestListUsers(t *testing.T) {
+	fake := &fakeUsersBackend{
+		kvs: map[string][]byte{
+			testPrefix + "/id/1001":                 []byte("key1;"),
+			testPrefix + "/value/key1;/node1":       []byte("1001"),
+			testPrefix + "/value/key1;/node2":       []byte("1001"),
+			testPrefix + "/value/key1;extra;/node3": []byte("1002"),
+		},
+	}
+	backend, err := NewKVStoreBackend(KVStoreBackendConfiguration{
+		BasePath: testPrefix,
+		Suffix:   "node1",
+		Typ:      TestAllocatorKey(""),
+		Backend:  fake,
+	})
+	require.NoError(t, err)
+
+	// Only the slave keys of the exact key string are reported, longer keys
+	// sharing the prefix are not.
+	users, err := backend.ListUsers(context.Background(), idpool.ID(1001))
+	require.NoError(t, err)
+	require.Equal(t, []string{"node1", "node2"}, users)
+
+	// An ID without a master key is an error.
+	_, err = backend.ListUsers(context.Background(), idpool.ID(2000))
+	require.ErrorContains(t, err, "no master key")
+}
+
+func TestGCDeleteJitter(t *testing.T) {
+	base := 100 * time.Millisecond
+	fraction := 0.25
+
+	// The jitter must stay within [0, fraction*base].
+	for i := 0; i < 1000; i++ {
+		jitter := gcDeleteJitter(base, fraction)
+		require.GreaterOrEqual(t, jitter, time.Duration(0))
+		require.LessOrEqual(t, jitter, 25*time.Millisecond)
+	}
+
+	// No jitter without a configured fraction or when the rate limiter did
+	// not block.
+	require.Equal(t, time.Duration(0), gcDeleteJitter(base, 0))
+	require.Equal(t, time.Duration(0), gcDeleteJitter(0, fraction))
+}
+
+// fakeConsistencyBackend implements just enough of kvstore.BackendOperations
+// for VerifyConsistency: listing prefixes and re-creating master keys.
+type fakeConsistencyBackend struct {
+	kvstore.BackendOperations
+
+	kvs     map[string]string
+	created map[string]string
+}
+
+func (f *fakeConsistencyBackend) ListPrefix(_ context.Context, prefix string) (kvstore.KeyValuePairs, error) {
+	pairs := kvstore.KeyValuePairs{}
+	for k, v := range f.kvs {
+		if strings.HasPrefix(k, prefix) {
+			pairs[k] = kvstore.Value{Data: []byte(v)}
+		}
+	}
+	return pairs, nil
+}
+
+func (f *fakeConsistencyBackend) CreateOnly(_ context.Context, key string, value []byte, _ bool) (bool, error) {
+	if _, ok := f.kvs[key]; ok {
+		return false, nil
+	}
+	f.kvs[key] = string(value)
+	f.created[key] = string(value)
+	return true, nil
+}
+
+func TestVerifyConsistency(t *testing.T) {
+	fake := &fakeConsistencyBackend{
+		kvs: map[string]string{
+			// Consistent identity.
+			"test/id/1001":           "keyA;",
+			"test/value/keyA;/node1": "1001",
+			// Master key without any slave key.
+			"test/id/1002": "keyB;",
+			// Slave key whose master is gone.
+			"test/value/keyC;/node2": "1003",
+		},
+		created: map[string]string{},
+	}
+	backend := &kvstoreBackend{
+		basePrefix:  "test",
+		idPrefix:    "test/id",
+		valuePrefix: "test/value",
+		backend:     fake,
+		idEncoder:   base10IDEncoder{},
+	}
+
+	inconsistencies, err := backend.VerifyConsistency(context.Background(), false)
+	require.NoError(t, err)
+	require.Equal(t, []Inconsistency{
+		{ID: 1002, Key: "keyB;"},
+		{ID: 1003, Key: "keyC;", MissingMaster: true},
+	}, inconsistencies)
+	require.Empty(t, fake.created)
+
+	// With repair enabled, the missing master key is re-created from the
+	// orphaned slave key.
+	inconsistencies, err = backend.VerifyConsistency(context.Background(), true)
+	require.NoError(t, err)
+	require.Equal(t, []Inconsistency{
+		{ID: 1002, Key: "keyB;"},
+		{ID: 1003, Key: "keyC;", MissingMaster: true, Repaired: true},
+	}, inconsistencies)
+	require.Equal(t, map[string]string{"test/id/1003": "keyC;"}, fake.created)
+}
+
+func TestIDEncoderRoundTrip(t *testing.T) {
+	for _, enc := range []IDEncoder{
+		base10IDEncoder{},
+		ZeroPadIDEncoder{Width: 10},
+	} {
+		for _, id := range []idpool.ID{1, 256, 65535, 1 << 24} {
+			decoded, err := enc.DecodeID(enc.EncodeID(id))
+			require.NoError(t, err)
+			require.Equal(t, id, decoded)
+		}
+
+		_, err := enc.DecodeID("not-a-number")
+		require.Error(t, err)
+	}
+
+	// The padded form orders lexicographically like it does numerically.
+	enc := ZeroPadIDEncoder{Width: 10}
+	require.Equal(t, "0000000256", enc.EncodeID(256))
+	require.Less(t, enc.EncodeID(256), enc.EncodeID(1024))
+
+	// Both encodings decode each other's output, so keyToID keeps working
+	// on keys written before an encoding switch.
+	decoded, err := base10IDEncoder{}.DecodeID(enc.EncodeID(256))
+	require.NoError(t, err)
+	require.Equal(t, idpool.ID(256), decoded)
+}
+
+func TestKeyToIDWithEncoder(t *testing.T) {
+	backend := &kvstoreBackend{
+		idPrefix:  "test/id",
+		idEncoder: ZeroPadIDEncoder{Width: 10},
+	}
+
+	require.Equal(t, "test/id/0000000256", backend.idPath(256))
+
+	id, err := backend.keyToID(backend.idPath(256))
+	require.NoError(t, err)
+	require.Equal(t, idpool.ID(256), id)
+
+	_, err = backend.keyToID("other/id/256")
+	require.Error(t, err)
+}
+
+// fakeUpdateKeyBackend stubs out the kvstore operations used by UpdateKey,
+// failing the first createFailures CreateOnly calls with the given error.
+type fakeUpdateKeyBackend struct {
+	kvstore.BackendOperations
+
+	createFailures int
+	createErr      error
+	createCalls    int
+	updateCalls    int
+}
+
+func (f *fakeUpdateKeyBackend) Encode(in []byte) string { return string(in) }
+
+func (f *fakeUpdateKeyBackend) CreateOnly(ctx context.Context, key string, value []byte, lease bool) (bool, error) {
+	f.createCalls++
+	if f.createCalls <= f.createFailures {
+		return false, f.createErr
+	}
+	return true, nil
+}
+
+func (f *fakeUpdateKeyBackend) UpdateIfDifferent(ctx context.Context, key string, value []byte, lease bool) (bool, error) {
+	f.updateCalls++
+	return false, nil
+}
+
+func TestUpdateKeyRetry(t *testing.T) {
+	newBackend := func(fake *fakeUpdateKeyBackend) *kvstoreBackend {
+		backend, err := NewKVStoreBackend(KVStoreBackendConfiguration{
+			BasePath:                testPrefix,
+			Suffix:                  "node1",
+			Typ:                     TestAllocatorKey(""),
+			Backend:                 fake,
+			UpdateKeyRetryBaseDelay: time.Microsecond,
+		})
+		require.NoError(t, err)
+		return backend
+	}
+
+	// Transient errors are retried until the call succeeds.
+	fake := &fakeUpdateKeyBackend{createFailures: 2, createErr: fmt.Errorf("connection refused")}
+	backend := newBackend(fake)
+	require.NoError(t, backend.UpdateKey(context.Background(), idpool.ID(10), TestAllocatorKey("foo"), true))
+	// Two failing attempts plus the successful one for the master key, one
+	// for the slave key.
+	require.Equal(t, 4, fake.createCalls)
+
+	// An exhausted retry budget surfaces the last error.
+	fake = &fakeUpdateKeyBackend{createFailures: 10, createErr: fmt.Errorf("connection refused")}
+	backend = newBackend(fake)
+	err := backend.UpdateKey(context.Background(), idpool.ID(10), TestAllocatorKey("foo"), true)
+	require.ErrorContains(t, err, "connection refused")
+	require.Equal(t, 1+defaultUpdateKeyRetries, fake.createCalls)
+
+	// A cancelled context is not transient and fails immediately.
+	fake = &fakeUpdateKeyBackend{createFailures: 10, createErr: context.Canceled}
+	backend = newBackend(fake)
+	err = backend.UpdateKey(context.Background(), idpool.ID(10), TestAllocatorKey("foo"), true)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, fake.createCalls)
+}
@@ -737,6 +737,31 @@ func (e *etcdClient) LockPath(ctx context.Context, path string) (KVLocker, error
 	return &etcdMutex{mutex: mu, onUnlock: release}, nil
 }
 
+// LockPathWithTTL acquires a lock on path backed by a dedicated session whose
+// lease expires after ttl, instead of a session from the shared lock lease
+// manager. The session is closed when the lock is released, orphaning the
+// lease and letting it expire server-side.
+func (e *etcdClient) LockPathWithTTL(ctx context.Context, path string, ttl time.Duration) (KVLocker, error) {
+	// Create the context first, so that the timeout also accounts for the time
+	// possibly required to establish the session.
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	session, err := concurrency.NewSession(e.client, concurrency.WithTTL(int(ttl.Seconds())))
+	if err != nil {
+		return nil, Hint(err)
+	}
+
+	mu := concurrency.NewMutex(session, path)
+	if err := mu.Lock(ctx); err != nil {
+		session.Close()
+		return nil, Hint(err)
+	}
+
+	release := func() { session.Close() }
+	return &etcdMutex{mutex: mu, onUnlock: release}, nil
+}
+
 func (e *etcdClient) DeletePrefix(ctx context.Context, path string) (err error) {
 	defer func() {
 		Trace("DeletePrefix", err, logrus.Fields{fieldPrefix: path})
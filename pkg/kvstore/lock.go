@@ -141,6 +141,43 @@ func LockPath(ctx context.Context, backend BackendOperations, path string) (l *L
 	return &Lock{kvLock: lock, path: path, id: id}, err
 }
 
+// pathLockerWithTTL is implemented by backends that can acquire a lock whose
+// lease has a caller-provided TTL instead of the backend's default lock
+// lease.
+type pathLockerWithTTL interface {
+	LockPathWithTTL(ctx context.Context, path string, ttl time.Duration) (KVLocker, error)
+}
+
+// LockPathWithTTL locks the specified path like LockPath, but requests a lock
+// lease with the given TTL from backends that support it. A zero TTL or a
+// backend without TTL support falls back to the default lock lease.
+//
+// The TTL only governs the kvstore side of the lock. The local stale lock
+// detection run by RunLockGC still forcefully releases locks held longer than
+// defaults.KVStoreStaleLockTimeout, so the TTL should stay below that
+// timeout.
+func LockPathWithTTL(ctx context.Context, backend BackendOperations, path string, ttl time.Duration) (l *Lock, err error) {
+	id, err := kvstoreLocks.lock(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var kvLock KVLocker
+	if b, ok := backend.(pathLockerWithTTL); ok && ttl > 0 {
+		kvLock, err = b.LockPathWithTTL(ctx, path, ttl)
+	} else {
+		kvLock, err = backend.LockPath(ctx, path)
+	}
+	if err != nil {
+		kvstoreLocks.unlock(path, id)
+		Trace("Failed to lock", err, logrus.Fields{fieldKey: path})
+		return nil, fmt.Errorf("error while locking path %s: %w", path, err)
+	}
+
+	Trace("Successful lock", err, logrus.Fields{fieldKey: path})
+	return &Lock{kvLock: kvLock, path: path, id: id}, nil
+}
+
 // RunLockGC inspects all local kvstore locks to determine whether they have
 // been held longer than the stale lock timeout, and if so, unlocks them
 // forceably.
@@ -47,6 +47,15 @@ const (
 	SVCNatPolicyNat64 = SVCNatPolicy("Nat64")
 )
 
+// SVCLoadBalancingAlgorithm is the load-balancing algorithm used to select a
+// backend for a service.
+type SVCLoadBalancingAlgorithm string
+
+const (
+	SVCLoadBalancingAlgorithmRandom = SVCLoadBalancingAlgorithm("random")
+	SVCLoadBalancingAlgorithmMaglev = SVCLoadBalancingAlgorithm("maglev")
+)
+
 // ServiceFlags is the datapath representation of the service flags that can be
 // used (lb{4,6}_service.flags)
 type ServiceFlags uint16
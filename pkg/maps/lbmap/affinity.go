@@ -29,11 +29,15 @@ var (
 )
 
 // initAffinity creates the BPF maps for implementing session affinity.
-func initAffinity(params InitParams) {
+func initAffinity(params InitParams) error {
+	if params.AffinityMapMaxEntries <= 0 {
+		return fmt.Errorf("invalid maximum number of affinity map entries %d", params.AffinityMapMaxEntries)
+	}
+
 	AffinityMapMaxEntries = params.AffinityMapMaxEntries
 
 	AffinityMatchMap = bpf.NewMap(
-		AffinityMatchMapName,
+		params.pinName(AffinityMatchMapName),
 		ebpf.Hash,
 		&AffinityMatchKey{},
 		&AffinityMatchValue{},
@@ -44,7 +48,7 @@ func initAffinity(params InitParams) {
 
 	if params.IPv4 {
 		Affinity4Map = bpf.NewMap(
-			Affinity4MapName,
+			params.pinName(Affinity4MapName),
 			ebpf.LRUHash,
 			&Affinity4Key{},
 			&AffinityValue{},
@@ -55,7 +59,7 @@ func initAffinity(params InitParams) {
 
 	if params.IPv6 {
 		Affinity6Map = bpf.NewMap(
-			Affinity6MapName,
+			params.pinName(Affinity6MapName),
 			ebpf.LRUHash,
 			&Affinity6Key{},
 			&AffinityValue{},
@@ -63,6 +67,8 @@ func initAffinity(params InitParams) {
 			0,
 		)
 	}
+
+	return nil
 }
 
 type AffinityMatchKey struct {
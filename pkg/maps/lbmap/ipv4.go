@@ -64,13 +64,18 @@ var (
 // initSVC constructs the IPv4 & IPv6 LB BPF maps used for Services. The maps
 // have their maximum entries configured. Note this does not create or open the
 // maps; it simply constructs the objects.
-func initSVC(params InitParams) {
+func initSVC(params InitParams) error {
+	if params.ServiceMapMaxEntries <= 0 || params.BackEndMapMaxEntries <= 0 || params.RevNatMapMaxEntries <= 0 {
+		return fmt.Errorf("invalid maximum number of map entries (service %d, backend %d, revNat %d)",
+			params.ServiceMapMaxEntries, params.BackEndMapMaxEntries, params.RevNatMapMaxEntries)
+	}
+
 	ServiceMapMaxEntries = params.ServiceMapMaxEntries
 	ServiceBackEndMapMaxEntries = params.BackEndMapMaxEntries
 	RevNatMapMaxEntries = params.RevNatMapMaxEntries
 
 	if params.IPv4 {
-		Service4MapV2 = bpf.NewMap(Service4MapV2Name,
+		Service4MapV2 = bpf.NewMap(params.pinName(Service4MapV2Name),
 			ebpf.Hash,
 			&Service4Key{},
 			&Service4Value{},
@@ -78,7 +83,7 @@ func initSVC(params InitParams) {
 			0,
 		).WithCache().WithPressureMetric().
 			WithEvents(option.Config.GetEventBufferConfig(Service4MapV2Name))
-		Backend4Map = bpf.NewMap(Backend4MapName,
+		Backend4Map = bpf.NewMap(params.pinName(Backend4MapName),
 			ebpf.Hash,
 			&Backend4Key{},
 			&Backend4Value{},
@@ -86,7 +91,7 @@ func initSVC(params InitParams) {
 			0,
 		).WithCache().WithPressureMetric().
 			WithEvents(option.Config.GetEventBufferConfig(Backend4MapName))
-		Backend4MapV2 = bpf.NewMap(Backend4MapV2Name,
+		Backend4MapV2 = bpf.NewMap(params.pinName(Backend4MapV2Name),
 			ebpf.Hash,
 			&Backend4KeyV3{},
 			&Backend4Value{},
@@ -94,7 +99,7 @@ func initSVC(params InitParams) {
 			0,
 		).WithCache().WithPressureMetric().
 			WithEvents(option.Config.GetEventBufferConfig(Backend4MapV2Name))
-		Backend4MapV3 = bpf.NewMap(Backend4MapV3Name,
+		Backend4MapV3 = bpf.NewMap(params.pinName(Backend4MapV3Name),
 			ebpf.Hash,
 			&Backend4KeyV3{},
 			&Backend4ValueV3{},
@@ -102,7 +107,7 @@ func initSVC(params InitParams) {
 			0,
 		).WithCache().WithPressureMetric().
 			WithEvents(option.Config.GetEventBufferConfig(Backend4MapV3Name))
-		RevNat4Map = bpf.NewMap(RevNat4MapName,
+		RevNat4Map = bpf.NewMap(params.pinName(RevNat4MapName),
 			ebpf.Hash,
 			&RevNat4Key{},
 			&RevNat4Value{},
@@ -113,7 +118,7 @@ func initSVC(params InitParams) {
 	}
 
 	if params.IPv6 {
-		Service6MapV2 = bpf.NewMap(Service6MapV2Name,
+		Service6MapV2 = bpf.NewMap(params.pinName(Service6MapV2Name),
 			ebpf.Hash,
 			&Service6Key{},
 			&Service6Value{},
@@ -121,7 +126,7 @@ func initSVC(params InitParams) {
 			0,
 		).WithCache().WithPressureMetric().
 			WithEvents(option.Config.GetEventBufferConfig(Service6MapV2Name))
-		Backend6Map = bpf.NewMap(Backend6MapName,
+		Backend6Map = bpf.NewMap(params.pinName(Backend6MapName),
 			ebpf.Hash,
 			&Backend6Key{},
 			&Backend6Value{},
@@ -129,7 +134,7 @@ func initSVC(params InitParams) {
 			0,
 		).WithCache().WithPressureMetric().
 			WithEvents(option.Config.GetEventBufferConfig(Backend6MapName))
-		Backend6MapV2 = bpf.NewMap(Backend6MapV2Name,
+		Backend6MapV2 = bpf.NewMap(params.pinName(Backend6MapV2Name),
 			ebpf.Hash,
 			&Backend6KeyV3{},
 			&Backend6Value{},
@@ -137,7 +142,7 @@ func initSVC(params InitParams) {
 			0,
 		).WithCache().WithPressureMetric().
 			WithEvents(option.Config.GetEventBufferConfig(Backend6MapV2Name))
-		Backend6MapV3 = bpf.NewMap(Backend6MapV3Name,
+		Backend6MapV3 = bpf.NewMap(params.pinName(Backend6MapV3Name),
 			ebpf.Hash,
 			&Backend6KeyV3{},
 			&Backend6ValueV3{},
@@ -145,7 +150,7 @@ func initSVC(params InitParams) {
 			0,
 		).WithCache().WithPressureMetric().
 			WithEvents(option.Config.GetEventBufferConfig(Backend6MapV3Name))
-		RevNat6Map = bpf.NewMap(RevNat6MapName,
+		RevNat6Map = bpf.NewMap(params.pinName(RevNat6MapName),
 			ebpf.Hash,
 			&RevNat6Key{},
 			&RevNat6Value{},
@@ -154,6 +159,8 @@ func initSVC(params InitParams) {
 		).WithCache().WithPressureMetric().
 			WithEvents(option.Config.GetEventBufferConfig(RevNat6MapName))
 	}
+
+	return nil
 }
 
 // The compile-time check for whether the structs implement the interfaces
@@ -415,6 +422,7 @@ func (b *Backend4Value) GetIPCluster() cmtypes.AddrCluster {
 }
 func (b *Backend4Value) GetPort() uint16 { return b.Port }
 func (b *Backend4Value) GetFlags() uint8 { return b.Flags }
+func (b *Backend4Value) SetFlags(flags uint8) { b.Flags = flags }
 func (b *Backend4Value) GetZone() uint8  { return 0 }
 
 func (v *Backend4Value) ToNetwork() BackendValue {
@@ -483,6 +491,7 @@ func (b *Backend4ValueV3) GetIPCluster() cmtypes.AddrCluster {
 }
 func (b *Backend4ValueV3) GetPort() uint16 { return b.Port }
 func (b *Backend4ValueV3) GetFlags() uint8 { return b.Flags }
+func (b *Backend4ValueV3) SetFlags(flags uint8) { b.Flags = flags }
 func (b *Backend4ValueV3) GetZone() uint8  { return b.Zone }
 
 func (v *Backend4ValueV3) ToNetwork() BackendValue {
@@ -4,9 +4,15 @@
 package lbmap
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net"
+	"net/netip"
+	"sort"
 	"strconv"
 
 	"github.com/sirupsen/logrus"
@@ -18,10 +24,13 @@ import (
 	datapathTypes "github.com/cilium/cilium/pkg/datapath/types"
 	"github.com/cilium/cilium/pkg/ip"
 	"github.com/cilium/cilium/pkg/loadbalancer"
+	"github.com/cilium/cilium/pkg/lock"
 	"github.com/cilium/cilium/pkg/logging"
 	"github.com/cilium/cilium/pkg/logging/logfields"
 	"github.com/cilium/cilium/pkg/maglev"
+	"github.com/cilium/cilium/pkg/metrics"
 	"github.com/cilium/cilium/pkg/option"
+	"github.com/cilium/cilium/pkg/time"
 	"github.com/cilium/cilium/pkg/u8proto"
 )
 
@@ -47,13 +56,30 @@ type LBBPFMap struct {
 	// pkg/service.go:(Service).UpsertService() lock.
 	maglevBackendIDsBuffer []loadbalancer.BackendID
 	maglevTableSize        uint64
+
+	// ipv4, ipv6 denote which address families the dump methods consider.
+	// Populated at construction time, so that library consumers constructing
+	// the map directly are not coupled to the option.Config globals.
+	ipv4, ipv6 bool
+
+	// readOnly is set by NewReadOnly. The underlying maps are opened with
+	// read-only file descriptors and every write method refuses to run.
+	readOnly bool
 }
 
+// New returns an LBBPFMap with the address families taken from the global
+// agent configuration.
 func New() *LBBPFMap {
+	return NewWithFamilies(option.Config.EnableIPv4, option.Config.EnableIPv6)
+}
+
+// NewWithFamilies returns an LBBPFMap dumping only the maps of the given
+// address families.
+func NewWithFamilies(ipv4, ipv6 bool) *LBBPFMap {
 	maglev := option.Config.NodePortAlg == option.NodePortAlgMaglev
 	maglevTableSize := option.Config.MaglevTableSize
 
-	m := &LBBPFMap{}
+	m := &LBBPFMap{ipv4: ipv4, ipv6: ipv6}
 
 	if maglev {
 		m.maglevBackendIDsBuffer = make([]loadbalancer.BackendID, maglevTableSize)
@@ -63,10 +89,45 @@ func New() *LBBPFMap {
 	return m
 }
 
+// NewReadOnly opens the pinned LB maps of the given address families with
+// read-only file descriptors and returns a map handle restricted to the dump
+// methods. Write methods return an error instead of touching the maps, which
+// makes the handle safe for offline inspection of a node's LB state.
+func NewReadOnly(ipv4, ipv6 bool) (*LBBPFMap, error) {
+	maps := []*bpf.Map{AffinityMatchMap}
+	if ipv4 {
+		maps = append(maps, Service4MapV2, Backend4MapV2, Backend4MapV3, RevNat4Map, SourceRange4Map)
+	}
+	if ipv6 {
+		maps = append(maps, Service6MapV2, Backend6MapV2, Backend6MapV3, RevNat6Map, SourceRange6Map)
+	}
+
+	for _, m := range maps {
+		if err := m.OpenReadOnly(); err != nil {
+			return nil, fmt.Errorf("opening %s read-only: %w", m.Name(), err)
+		}
+	}
+
+	return &LBBPFMap{ipv4: ipv4, ipv6: ipv6, readOnly: true}, nil
+}
+
+// checkWritable returns an error when the handle was opened via NewReadOnly
+// and must not issue writes.
+func (lbmap *LBBPFMap) checkWritable() error {
+	if lbmap.readOnly {
+		return fmt.Errorf("lbmap is opened read-only for inspection, refusing write")
+	}
+	return nil
+}
+
 func (lbmap *LBBPFMap) upsertServiceProto(p *datapathTypes.UpsertServiceParams, ipv6 bool) error {
 	var svcKey ServiceKey
 	var svcVal ServiceValue
 
+	if err := checkRevNatID(loadbalancer.ID(p.ID)); err != nil {
+		return err
+	}
+
 	// Backends should be added to the backend maps for the case when:
 	// - Plain IPv6 (to IPv6) or IPv4 (to IPv4) service.
 	// - IPv4 to IPv6 will only have a dummy IPv4 service entry (0 backends)
@@ -124,13 +185,20 @@ func (lbmap *LBBPFMap) upsertServiceProto(p *datapathTypes.UpsertServiceParams,
 	zeroValue.SetRevNat(int(p.ID)) // TODO change to uint16
 	revNATKey := zeroValue.RevNatKey()
 	revNATValue := svcKey.RevNatValue()
-	if err := updateRevNatLocked(revNATKey, revNATValue); err != nil {
+	skipRevNat := p.SkipRevNatForSurrogate && svcKey.IsSurrogate()
+	if skipRevNat {
+		// The surrogate entry may have been written with a revNAT entry
+		// before the option was enabled, remove the stale entry.
+		deleteRevNatLocked(revNATKey)
+	} else if err := updateRevNatLocked(revNATKey, revNATValue); err != nil {
 		return fmt.Errorf("Unable to update reverse NAT %+v => %+v: %w", revNATKey, revNATValue, err)
 	}
 
 	if err := updateMasterService(svcKey, svcVal.New().(ServiceValue), len(backends), int(p.ID), p.Type, p.ExtLocal, p.IntLocal, p.NatPolicy,
 		p.SessionAffinity, p.SessionAffinityTimeoutSec, p.CheckSourceRange, p.L7LBProxyPort, p.LoopbackHostport); err != nil {
-		deleteRevNatLocked(revNATKey)
+		if !skipRevNat {
+			deleteRevNatLocked(revNATKey)
+		}
 		return fmt.Errorf("Unable to update service %+v: %w", svcKey, err)
 	}
 
@@ -149,6 +217,63 @@ func (lbmap *LBBPFMap) upsertServiceProto(p *datapathTypes.UpsertServiceParams,
 	return nil
 }
 
+// validateBackendProtocols checks that all backends of the given service share
+// a single L4 protocol. The datapath keys services and backends with
+// u8proto.ANY, so a mix of, e.g., TCP and UDP backends under one service would
+// be installed without complaint and silently break load-balancing. The
+// expected protocol is taken from the first backend carrying one; backends
+// with no protocol (NONE) act as wildcards.
+func validateBackendProtocols(p *datapathTypes.UpsertServiceParams) error {
+	expected := loadbalancer.NONE
+	var mismatched []loadbalancer.BackendID
+	for _, b := range p.ActiveBackends {
+		if b.Protocol == loadbalancer.NONE {
+			continue
+		}
+		if expected == loadbalancer.NONE {
+			expected = b.Protocol
+			continue
+		}
+		if b.Protocol != expected {
+			mismatched = append(mismatched, b.ID)
+		}
+	}
+	if len(mismatched) > 0 {
+		sort.Slice(mismatched, func(i, j int) bool { return mismatched[i] < mismatched[j] })
+		return fmt.Errorf("service %d mixes backend protocols: backends %v do not match protocol %s",
+			p.ID, mismatched, expected)
+	}
+	return nil
+}
+
+// validateNat46Backends checks that every backend of a NAT46 service encodes
+// as a valid IPv4-in-IPv6 address when added to the v6 backend map. The
+// datapath relies on the v4-mapped encoding to recirculate the packet into
+// the IPv4 path, so plain IPv6 backends under a NAT46 frontend would be
+// silently unreachable.
+func validateNat46Backends(p *datapathTypes.UpsertServiceParams) error {
+	if p.NatPolicy != loadbalancer.SVCNatPolicyNat46 {
+		return nil
+	}
+	for _, b := range p.ActiveBackends {
+		if err := validateNat46BackendAddr(b.AddrCluster); err != nil {
+			return fmt.Errorf("service %d: %w", p.ID, err)
+		}
+	}
+	return nil
+}
+
+// validateNat46BackendAddr returns an error unless the given backend address
+// yields a v4-mapped address under the 16-byte encoding used by the v6
+// backend map. Plain IPv4 addresses and already mapped ::ffff:0:0/96
+// addresses qualify.
+func validateNat46BackendAddr(addrCluster cmtypes.AddrCluster) error {
+	if netip.AddrFrom16(addrCluster.Addr().As16()).Is4In6() {
+		return nil
+	}
+	return fmt.Errorf("backend address %s is not a valid IPv4-in-IPv6 address for a NAT46 service", addrCluster.String())
+}
+
 // UpsertService inserts or updates the given service in a BPF map.
 //
 // The corresponding backend entries (identified with the given backendIDs)
@@ -165,9 +290,21 @@ func (lbmap *LBBPFMap) upsertServiceProto(p *datapathTypes.UpsertServiceParams,
 // The non-active backends are, however, populated in the service map so that they
 // can be restored upon agent restart along with their state.
 func (lbmap *LBBPFMap) UpsertService(p *datapathTypes.UpsertServiceParams) error {
+	if err := lbmap.checkWritable(); err != nil {
+		return err
+	}
 	if p.ID == 0 {
 		return fmt.Errorf("Invalid svc ID 0")
 	}
+	if err := validateBackendProtocols(p); err != nil {
+		return err
+	}
+	if err := validateNat46Backends(p); err != nil {
+		return err
+	}
+	if p.QuarantinedBackends > 0 {
+		metrics.ServiceQuarantinedBackendsTotal.WithLabelValues(string(p.Type)).Add(float64(p.QuarantinedBackends))
+	}
 	if err := lbmap.upsertServiceProto(p,
 		p.IPv6 || p.NatPolicy == loadbalancer.SVCNatPolicyNat46); err != nil {
 		return err
@@ -180,9 +317,155 @@ func (lbmap *LBBPFMap) UpsertService(p *datapathTypes.UpsertServiceParams) error
 	return nil
 }
 
+// ReplaceServiceBackends replaces the whole backend set of the given service,
+// minimizing the window in which the datapath sees a mix of old and new
+// backends. Backend slots beyond the previous backend count are written first;
+// they stay invisible to the datapath until the master entry's backend count
+// is flipped in a single map update. Only the overwrite of the slots within
+// the previously visible range remains incremental, as individual slot writes
+// cannot be batched.
+//
+// During the cutover the service map temporarily holds the union of the old
+// and new slot ranges, i.e. max(old, new) backend slots per family. If the map
+// runs out of capacity while writing the shadow slots (E2BIG), the replacement
+// falls back to the in-place update of UpsertService.
+func (lbmap *LBBPFMap) ReplaceServiceBackends(p *datapathTypes.UpsertServiceParams) error {
+	if err := lbmap.checkWritable(); err != nil {
+		return err
+	}
+	if p.ID == 0 {
+		return fmt.Errorf("Invalid svc ID 0")
+	}
+	if err := validateBackendProtocols(p); err != nil {
+		return err
+	}
+	if err := validateNat46Backends(p); err != nil {
+		return err
+	}
+	if err := lbmap.replaceServiceProto(p,
+		p.IPv6 || p.NatPolicy == loadbalancer.SVCNatPolicyNat46); err != nil {
+		return err
+	}
+	if p.NatPolicy == loadbalancer.SVCNatPolicyNat46 {
+		if err := lbmap.replaceServiceProto(p, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (lbmap *LBBPFMap) replaceServiceProto(p *datapathTypes.UpsertServiceParams, ipv6 bool) error {
+	var svcKey ServiceKey
+	var svcVal ServiceValue
+
+	backendsOk := ipv6 || !ipv6 && p.NatPolicy != loadbalancer.SVCNatPolicyNat46
+
+	if ipv6 {
+		svcKey = NewService6Key(p.IP, p.Port, u8proto.ANY, p.Scope, 0)
+		svcVal = &Service6Value{}
+	} else {
+		svcKey = NewService4Key(p.IP, p.Port, u8proto.ANY, p.Scope, 0)
+		svcVal = &Service4Value{}
+	}
+
+	backends := make(map[string]*loadbalancer.Backend)
+	if backendsOk {
+		backends = p.ActiveBackends
+		if len(p.PreferredBackends) > 0 {
+			backends = p.PreferredBackends
+		}
+		if p.UseMaglev && len(backends) != 0 {
+			if err := lbmap.UpsertMaglevLookupTable(p.ID, backends, ipv6); err != nil {
+				return err
+			}
+		}
+
+		backendIDs := p.GetOrderedBackends()
+
+		writeSlot := func(slot int, backendID loadbalancer.BackendID) error {
+			if backendID == 0 {
+				return fmt.Errorf("Invalid backend ID 0")
+			}
+			svcVal.SetBackendID(backendID)
+			svcVal.SetRevNat(int(p.ID))
+			svcKey.SetBackendSlot(slot)
+			return updateServiceEndpoint(svcKey, svcVal)
+		}
+
+		// Write the shadow slot range beyond the previously visible backend
+		// count first. These entries are not consulted by the datapath until
+		// the master entry below raises the backend count.
+		for i, backendID := range backendIDs {
+			slot := i + 1
+			if slot <= p.PrevBackendsCount {
+				continue
+			}
+			if err := writeSlot(slot, backendID); err != nil {
+				if errors.Is(err, unix.E2BIG) {
+					log.WithField(logfields.ServiceID, p.ID).
+						Warn("Insufficient map capacity for backend replacement, falling back to in-place update")
+					return lbmap.upsertServiceProto(p, ipv6)
+				}
+				return fmt.Errorf("Unable to update service entry %+v => %+v: %w", svcKey, svcVal, err)
+			}
+		}
+
+		// Overwrite the previously visible slots with the new backend set.
+		for i, backendID := range backendIDs {
+			slot := i + 1
+			if slot > p.PrevBackendsCount {
+				break
+			}
+			if err := writeSlot(slot, backendID); err != nil {
+				return fmt.Errorf("Unable to update service entry %+v => %+v: %w", svcKey, svcVal, err)
+			}
+		}
+	}
+
+	zeroValue := svcVal.New().(ServiceValue)
+	zeroValue.SetRevNat(int(p.ID))
+	revNATKey := zeroValue.RevNatKey()
+	revNATValue := svcKey.RevNatValue()
+	skipRevNat := p.SkipRevNatForSurrogate && svcKey.IsSurrogate()
+	if skipRevNat {
+		deleteRevNatLocked(revNATKey)
+	} else if err := updateRevNatLocked(revNATKey, revNATValue); err != nil {
+		return fmt.Errorf("Unable to update reverse NAT %+v => %+v: %w", revNATKey, revNATValue, err)
+	}
+
+	// A single master update flips the backend count, making the new set
+	// authoritative for the datapath.
+	if err := updateMasterService(svcKey, svcVal.New().(ServiceValue), len(backends), int(p.ID), p.Type, p.ExtLocal, p.IntLocal, p.NatPolicy,
+		p.SessionAffinity, p.SessionAffinityTimeoutSec, p.CheckSourceRange, p.L7LBProxyPort, p.LoopbackHostport); err != nil {
+		if !skipRevNat {
+			deleteRevNatLocked(revNATKey)
+		}
+		return fmt.Errorf("Unable to update service %+v: %w", svcKey, err)
+	}
+
+	// Remove slots of the old range no longer covered by the new backend set.
+	if backendsOk {
+		total := len(p.GetOrderedBackends())
+		for i := total + 1; i <= p.PrevBackendsCount; i++ {
+			svcKey.SetBackendSlot(i)
+			if err := deleteServiceLocked(svcKey); err != nil {
+				log.WithFields(logrus.Fields{
+					logfields.ServiceKey:  svcKey,
+					logfields.BackendSlot: svcKey.GetBackendSlot(),
+				}).WithError(err).Warn("Unable to delete service entry from BPF map")
+			}
+		}
+	}
+
+	return nil
+}
+
 // UpsertMaglevLookupTable calculates Maglev lookup table for given backends, and
 // inserts into the Maglev BPF map.
 func (lbmap *LBBPFMap) UpsertMaglevLookupTable(svcID uint16, backends map[string]*loadbalancer.Backend, ipv6 bool) error {
+	if err := lbmap.checkWritable(); err != nil {
+		return err
+	}
 	table := maglev.GetLookupTable(backends, lbmap.maglevTableSize)
 	for i, id := range table {
 		lbmap.maglevBackendIDsBuffer[i] = loadbalancer.BackendID(id)
@@ -194,6 +477,45 @@ func (lbmap *LBBPFMap) UpsertMaglevLookupTable(svcID uint16, backends map[string
 	return nil
 }
 
+// SetServiceAlgorithm migrates a live service between the random and maglev
+// load-balancing algorithms. The datapath selects the algorithm globally (the
+// service entries carry no per-service algorithm flag), so the only state to
+// reconcile is the maglev lookup table: switching to maglev populates the
+// table for the service from the given backends, switching away deletes it.
+// The service and backend entries themselves are left untouched, so existing
+// connections are not disturbed by a delete and re-upsert cycle.
+func (lbmap *LBBPFMap) SetServiceAlgorithm(svc loadbalancer.L3n4AddrID,
+	alg loadbalancer.SVCLoadBalancingAlgorithm, backends map[string]*loadbalancer.Backend) error {
+	if err := lbmap.checkWritable(); err != nil {
+		return err
+	}
+	if svc.ID == 0 {
+		return fmt.Errorf("Invalid svc ID 0")
+	}
+	if err := checkRevNatID(svc.ID); err != nil {
+		return err
+	}
+
+	ipv6 := svc.IsIPv6()
+	switch alg {
+	case loadbalancer.SVCLoadBalancingAlgorithmMaglev:
+		if lbmap.maglevTableSize == 0 {
+			return fmt.Errorf("unable to switch service %d to maglev: maglev maps not initialized", svc.ID)
+		}
+		if len(backends) == 0 {
+			return nil
+		}
+		return lbmap.UpsertMaglevLookupTable(uint16(svc.ID), backends, ipv6)
+	case loadbalancer.SVCLoadBalancingAlgorithmRandom:
+		if err := deleteMaglevTable(ipv6, uint16(svc.ID)); err != nil {
+			return fmt.Errorf("Unable to delete maglev lookup table %d: %w", svc.ID, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown load-balancing algorithm %q", alg)
+	}
+}
+
 func deleteServiceProto(svc loadbalancer.L3n4AddrID, backendCount int, useMaglev, ipv6 bool) error {
 	var (
 		svcKey    ServiceKey
@@ -225,15 +547,23 @@ func deleteServiceProto(svc loadbalancer.L3n4AddrID, backendCount int, useMaglev
 		return fmt.Errorf("Unable to delete revNAT entry %+v: %w", revNATKey, err)
 	}
 
+	releaseL7ProxyPort(int(svc.ID))
+
 	return nil
 }
 
 // DeleteService removes given service from a BPF map.
-func (*LBBPFMap) DeleteService(svc loadbalancer.L3n4AddrID, backendCount int, useMaglev bool,
+func (lbmap *LBBPFMap) DeleteService(svc loadbalancer.L3n4AddrID, backendCount int, useMaglev bool,
 	natPolicy loadbalancer.SVCNatPolicy) error {
+	if err := lbmap.checkWritable(); err != nil {
+		return err
+	}
 	if svc.ID == 0 {
 		return fmt.Errorf("Invalid svc ID 0")
 	}
+	if err := checkRevNatID(svc.ID); err != nil {
+		return err
+	}
 	if err := deleteServiceProto(svc, backendCount, useMaglev,
 		svc.IsIPv6() || natPolicy == loadbalancer.SVCNatPolicyNat46); err != nil {
 		return err
@@ -246,9 +576,244 @@ func (*LBBPFMap) DeleteService(svc loadbalancer.L3n4AddrID, backendCount int, us
 	return nil
 }
 
+// ListQuarantinedBackends returns the IDs of the given service's backends
+// whose backend map entry carries the quarantined state. Non-active backends
+// are kept in the service slots past the master key's backend count, so the
+// slots are walked until the first missing one and each referenced backend's
+// flags are inspected.
+func (*LBBPFMap) ListQuarantinedBackends(svc loadbalancer.L3n4AddrID) ([]loadbalancer.BackendID, error) {
+	var svcKey ServiceKey
+
+	ipv6 := svc.IsIPv6()
+	if ipv6 {
+		svcKey = NewService6Key(svc.AddrCluster.AsNetIP(), svc.Port, u8proto.ANY, svc.Scope, 0)
+	} else {
+		svcKey = NewService4Key(svc.AddrCluster.AsNetIP(), svc.Port, u8proto.ANY, svc.Scope, 0)
+	}
+
+	var quarantined []loadbalancer.BackendID
+	for slot := 1; ; slot++ {
+		svcKey.SetBackendSlot(slot)
+		v, err := svcKey.Map().Lookup(svcKey.ToNetwork())
+		if err != nil {
+			// The first missing slot terminates the walk, the slots of
+			// a service are contiguous.
+			break
+		}
+		backendID := v.(ServiceValue).ToHost().GetBackendID()
+
+		var backendKey BackendKey
+		if ipv6 {
+			backendKey = NewBackend6KeyV3(backendID)
+		} else {
+			backendKey = NewBackend4KeyV3(backendID)
+		}
+		bv, err := backendKey.Map().Lookup(backendKey)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to lookup backend %d of service %s: %w", backendID, svc.String(), err)
+		}
+		flags := bv.(BackendValue).GetFlags()
+		if loadbalancer.GetBackendStateFromFlags(flags) == loadbalancer.BackendStateQuarantined {
+			quarantined = append(quarantined, backendID)
+		}
+	}
+
+	return quarantined, nil
+}
+
+// QuarantineBackend flips the quarantine state of a single backend of the
+// given service without re-upserting the whole service. The backend map
+// entry's state flag is set to quarantined (or back to active) and the
+// service's slots are reordered so that the backend moves out of (or back
+// into) the active range covered by the master entry's backend count, which
+// is adjusted accordingly; non-active backends are kept in the slots past
+// that count, there is no separate quarantine counter in the master entry.
+//
+// The update is a read-modify-write across several map entries and is not
+// atomic: a concurrent UpsertService of the same service may interleave and
+// lose either update. Callers must serialize with service writers (in
+// practice, by holding the service manager lock around all service map
+// updates).
+func (lbmap *LBBPFMap) QuarantineBackend(svc loadbalancer.L3n4AddrID, backendID loadbalancer.BackendID, quarantined bool) error {
+	if err := lbmap.checkWritable(); err != nil {
+		return err
+	}
+	if backendID == 0 {
+		return fmt.Errorf("Invalid backend ID 0")
+	}
+
+	var svcKey ServiceKey
+
+	ipv6 := svc.IsIPv6()
+	if ipv6 {
+		svcKey = NewService6Key(svc.AddrCluster.AsNetIP(), svc.Port, u8proto.ANY, svc.Scope, 0)
+	} else {
+		svcKey = NewService4Key(svc.AddrCluster.AsNetIP(), svc.Port, u8proto.ANY, svc.Scope, 0)
+	}
+
+	svcKey.SetBackendSlot(0)
+	m, err := svcKey.Map().Lookup(svcKey.ToNetwork())
+	if err != nil {
+		return fmt.Errorf("Unable to lookup service %s: %w", svc.String(), err)
+	}
+	masterVal := m.(ServiceValue).ToHost()
+	count := masterVal.GetCount()
+
+	// Collect the slot values; the slots of a service are contiguous, the
+	// first missing one terminates the walk.
+	var slotVals []ServiceValue
+	for slot := 1; ; slot++ {
+		svcKey.SetBackendSlot(slot)
+		v, err := svcKey.Map().Lookup(svcKey.ToNetwork())
+		if err != nil {
+			break
+		}
+		slotVals = append(slotVals, v.(ServiceValue).ToHost())
+	}
+
+	slot := 0
+	for i, v := range slotVals {
+		if v.GetBackendID() == backendID {
+			slot = i + 1
+			break
+		}
+	}
+	if slot == 0 {
+		return fmt.Errorf("Backend %d is not a backend of service %s", backendID, svc.String())
+	}
+
+	var backendKey BackendKey
+	if ipv6 {
+		backendKey = NewBackend6KeyV3(backendID)
+	} else {
+		backendKey = NewBackend4KeyV3(backendID)
+	}
+	bv, err := backendKey.Map().Lookup(backendKey)
+	if err != nil {
+		return fmt.Errorf("Unable to lookup backend %d of service %s: %w", backendID, svc.String(), err)
+	}
+	backendVal := bv.(BackendValue)
+
+	state := loadbalancer.BackendStateActive
+	if quarantined {
+		state = loadbalancer.BackendStateQuarantined
+	}
+	if loadbalancer.GetBackendStateFromFlags(backendVal.GetFlags()) == state {
+		return nil
+	}
+	// The looked-up value is already in network byte order and the flags
+	// are a single byte, so it is written back directly.
+	backendVal.SetFlags(uint8(loadbalancer.NewBackendFlags(state)))
+	if err := backendKey.Map().Update(backendKey, backendVal); err != nil {
+		return fmt.Errorf("Unable to update backend %d of service %s: %w", backendID, svc.String(), err)
+	}
+
+	writeSlot := func(slot int, v ServiceValue) error {
+		svcKey.SetBackendSlot(slot)
+		if err := updateServiceEndpoint(svcKey, v); err != nil {
+			return fmt.Errorf("Unable to update slot %d of service %s: %w", slot, svc.String(), err)
+		}
+		return nil
+	}
+
+	// Move the backend across the active/non-active boundary by swapping
+	// it with the slot adjacent to the boundary. The master entry's count
+	// is flipped last, so the slot contents are already consistent when
+	// the boundary moves.
+	switch {
+	case quarantined && slot <= count:
+		if other := count; other != slot {
+			if err := writeSlot(slot, slotVals[other-1]); err != nil {
+				return err
+			}
+			if err := writeSlot(other, slotVals[slot-1]); err != nil {
+				return err
+			}
+		}
+		count--
+		metrics.ServiceQuarantinedBackendsTotal.WithLabelValues(
+			string(loadbalancer.ServiceFlags(masterVal.GetFlags()).SVCType())).Inc()
+	case !quarantined && slot > count:
+		if other := count + 1; other != slot {
+			if err := writeSlot(slot, slotVals[other-1]); err != nil {
+				return err
+			}
+			if err := writeSlot(other, slotVals[slot-1]); err != nil {
+				return err
+			}
+		}
+		count++
+	default:
+		// The backend already sits on the right side of the boundary,
+		// e.g. it was non-active for another reason before being
+		// quarantined; only the flags needed flipping.
+		return nil
+	}
+
+	masterVal.SetCount(count)
+	svcKey.SetBackendSlot(0)
+	if err := updateServiceEndpoint(svcKey, masterVal); err != nil {
+		return fmt.Errorf("Unable to update service %s: %w", svc.String(), err)
+	}
+
+	return nil
+}
+
+// DeleteServiceAndOrphanBackends removes the given service from the BPF maps
+// just like DeleteService and afterwards deletes each of the listed backends
+// that is no longer referenced by any remaining service entry. Backends still
+// shared with other services are left in place. Returns the IDs of the
+// backends which were actually deleted.
+func (lbmap *LBBPFMap) DeleteServiceAndOrphanBackends(svc loadbalancer.L3n4AddrID, backendCount int, useMaglev bool,
+	natPolicy loadbalancer.SVCNatPolicy, backendIDs []loadbalancer.BackendID) ([]loadbalancer.BackendID, error) {
+	if err := lbmap.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := lbmap.DeleteService(svc, backendCount, useMaglev, natPolicy); err != nil {
+		return nil, err
+	}
+
+	referenced := map[loadbalancer.BackendID]struct{}{}
+	parseSVCEntries := func(key bpf.MapKey, value bpf.MapValue) {
+		svcKey := key.(ServiceKey).ToHost()
+		// The master entry at slot 0 does not reference a backend.
+		if svcKey.GetBackendSlot() == 0 {
+			return
+		}
+		referenced[value.(ServiceValue).ToHost().GetBackendID()] = struct{}{}
+	}
+
+	if option.Config.EnableIPv4 {
+		if err := Service4MapV2.DumpWithCallback(parseSVCEntries); err != nil {
+			return nil, fmt.Errorf("Unable to dump lb4 services map: %w", err)
+		}
+	}
+	if option.Config.EnableIPv6 {
+		if err := Service6MapV2.DumpWithCallback(parseSVCEntries); err != nil {
+			return nil, fmt.Errorf("Unable to dump lb6 services map: %w", err)
+		}
+	}
+
+	deleted := make([]loadbalancer.BackendID, 0, len(backendIDs))
+	for _, id := range backendIDs {
+		if _, ok := referenced[id]; ok {
+			continue
+		}
+		if err := lbmap.DeleteBackendByID(id); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, id)
+	}
+
+	return deleted, nil
+}
+
 // AddBackend adds a backend into a BPF map. ipv6 indicates if the backend needs
 // to be added in the v4 or v6 backend map.
-func (*LBBPFMap) AddBackend(b *loadbalancer.Backend, ipv6 bool) error {
+func (lbmap *LBBPFMap) AddBackend(b *loadbalancer.Backend, ipv6 bool) error {
+	if err := lbmap.checkWritable(); err != nil {
+		return err
+	}
 	var (
 		backend Backend
 		err     error
@@ -264,10 +829,48 @@ func (*LBBPFMap) AddBackend(b *loadbalancer.Backend, ipv6 bool) error {
 	return nil
 }
 
+// AddBackendPreservingState adds a backend into a BPF map like AddBackend,
+// but if an entry for the backend already exists, the state flags stored in
+// the map are carried over instead of being overwritten with the state in b.
+// This allows restoring a backend's address, port and zone without clobbering
+// e.g. a quarantine flag set by a concurrent health checker.
+//
+// The carry-over is a read-modify-write of the map entry and is not atomic:
+// a state update applied between the lookup and the write below is lost.
+// Callers must serialize with writers of backend state (in practice, by
+// holding the service manager lock around all backend map updates).
+func (lbmap *LBBPFMap) AddBackendPreservingState(b *loadbalancer.Backend, ipv6 bool) error {
+	if err := lbmap.checkWritable(); err != nil {
+		return err
+	}
+	var (
+		backend Backend
+		err     error
+	)
+
+	if backend, err = getBackend(b, ipv6); err != nil {
+		return err
+	}
+	if err := backend.Map().OpenOrCreate(); err != nil {
+		return err
+	}
+	if prev, err := backend.Map().Lookup(backend.GetKey()); err == nil {
+		backend.GetValue().SetFlags(prev.(BackendValue).GetFlags())
+	}
+	if err := updateBackend(backend); err != nil {
+		return fmt.Errorf("unable to add backend %+v: %w", backend, err)
+	}
+
+	return nil
+}
+
 // UpdateBackendWithState updates the state for the given backend.
 //
 // This function should only be called to update backend's state.
-func (*LBBPFMap) UpdateBackendWithState(b *loadbalancer.Backend) error {
+func (lbmap *LBBPFMap) UpdateBackendWithState(b *loadbalancer.Backend) error {
+	if err := lbmap.checkWritable(); err != nil {
+		return err
+	}
 	var (
 		backend Backend
 		err     error
@@ -300,7 +903,10 @@ func deleteBackendByIDFamily(id loadbalancer.BackendID, ipv6 bool) error {
 }
 
 // DeleteBackendByID removes a backend identified with the given ID from a BPF map.
-func (*LBBPFMap) DeleteBackendByID(id loadbalancer.BackendID) error {
+func (lbmap *LBBPFMap) DeleteBackendByID(id loadbalancer.BackendID) error {
+	if err := lbmap.checkWritable(); err != nil {
+		return err
+	}
 	if id == 0 {
 		return fmt.Errorf("Invalid backend ID 0")
 	}
@@ -316,15 +922,74 @@ func (*LBBPFMap) DeleteBackendByID(id loadbalancer.BackendID) error {
 	return nil
 }
 
+// DeleteBackendByIDs removes the backends with the given IDs from the backend
+// maps of both address families. One error is returned per ID which failed to
+// be removed, so that mass cleanup during large service deletions can report
+// partial failures instead of aborting at the first. The bpf map wrapper has
+// no batched delete operation, so the deletions are issued per entry, with
+// SilentDelete keeping already absent entries from counting as failures.
+func (lbmap *LBBPFMap) DeleteBackendByIDs(ids []loadbalancer.BackendID) []error {
+	if err := lbmap.checkWritable(); err != nil {
+		return []error{err}
+	}
+	var errs []error
+	for _, id := range ids {
+		if id == 0 {
+			errs = append(errs, fmt.Errorf("Invalid backend ID 0"))
+			continue
+		}
+
+		// The backend could be a backend for a NAT64 service, therefore
+		// attempt to remove from both backend maps.
+		var err error
+		if option.Config.EnableIPv6 {
+			err = errors.Join(err, deleteBackendByIDFamily(id, true))
+		}
+		if option.Config.EnableIPv4 {
+			err = errors.Join(err, deleteBackendByIDFamily(id, false))
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
 // DeleteAffinityMatch removes the affinity match for the given svc and backend ID
 // tuple from the BPF map
-func (*LBBPFMap) DeleteAffinityMatch(revNATID uint16, backendID loadbalancer.BackendID) error {
+func (lbmap *LBBPFMap) DeleteAffinityMatch(revNATID uint16, backendID loadbalancer.BackendID) error {
+	if err := lbmap.checkWritable(); err != nil {
+		return err
+	}
 	return AffinityMatchMap.Delete(
 		NewAffinityMatchKey(revNATID, backendID).ToNetwork())
 }
 
+// Map name labels under which update durations are recorded in the
+// LBMapUpdateDuration histogram.
+const (
+	updateMetricService     = "service"
+	updateMetricBackend     = "backend"
+	updateMetricRevNat      = "revnat"
+	updateMetricAffinity    = "affinity"
+	updateMetricSourceRange = "sourcerange"
+)
+
+// observeUpdateDuration records the duration of a BPF map update under the
+// given map name label. The metric is disabled by default; the IsEnabled
+// check keeps the disabled path free of per-call work.
+func observeUpdateDuration(mapName string, start time.Time) {
+	if metrics.LBMapUpdateDuration.IsEnabled() {
+		metrics.LBMapUpdateDuration.WithLabelValues(mapName).Observe(time.Since(start).Seconds())
+	}
+}
+
 // AddAffinityMatch adds the given affinity match to the BPF map.
-func (*LBBPFMap) AddAffinityMatch(revNATID uint16, backendID loadbalancer.BackendID) error {
+func (lbmap *LBBPFMap) AddAffinityMatch(revNATID uint16, backendID loadbalancer.BackendID) error {
+	if err := lbmap.checkWritable(); err != nil {
+		return err
+	}
+	defer observeUpdateDuration(updateMetricAffinity, time.Now())
 	return AffinityMatchMap.Update(
 		NewAffinityMatchKey(revNATID, backendID).ToNetwork(),
 		&AffinityMatchValue{})
@@ -362,18 +1027,120 @@ func (*LBBPFMap) DumpSourceRanges(ipv6 bool) (datapathTypes.SourceRangeSetByServ
 		if _, found := ret[revNATID]; !found {
 			ret[revNATID] = []*cidr.CIDR{}
 		}
-		ret[revNATID] = append(ret[revNATID], k.GetCIDR())
+		ret[revNATID] = append(ret[revNATID], k.GetCIDR())
+	}
+
+	m := SourceRange4Map
+	if ipv6 {
+		m = SourceRange6Map
+	}
+	if err := m.DumpWithCallback(parser); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// CompactSourceRanges removes source range entries whose revNAT ID is not in
+// validRevNATIDs from the source range maps of the enabled address families.
+// It returns the number of entries deleted. Service deletions that missed the
+// source range cleanup leave such orphans behind; this gives the service
+// manager a way to garbage collect them.
+func (lbmap *LBBPFMap) CompactSourceRanges(validRevNATIDs map[uint16]struct{}) (deleted int, err error) {
+	if err := lbmap.checkWritable(); err != nil {
+		return 0, err
+	}
+	maps := []*bpf.Map{}
+	if lbmap.ipv4 {
+		maps = append(maps, SourceRange4Map)
+	}
+	if lbmap.ipv6 {
+		maps = append(maps, SourceRange6Map)
+	}
+
+	for _, m := range maps {
+		// Collect the stale keys first, deleting while iterating an LPM
+		// map skips entries.
+		var stale []bpf.MapKey
+		parser := func(key bpf.MapKey, _ bpf.MapValue) {
+			revNATID := key.(SourceRangeKey).ToHost().GetRevNATID()
+			if _, valid := validRevNATIDs[revNATID]; !valid {
+				stale = append(stale, key)
+			}
+		}
+		if err := m.DumpWithCallback(parser); err != nil {
+			return deleted, fmt.Errorf("dumping %s: %w", m.Name(), err)
+		}
+
+		for _, key := range stale {
+			if err := m.Delete(key); err != nil {
+				return deleted, fmt.Errorf("deleting stale entry %s from %s: %w", key, m.Name(), err)
+			}
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// RepairMissingRevNat recreates revNAT entries that are missing for existing
+// service frontends, reconstructing each entry from the service's own key and
+// revNAT ID instead of deleting the affected service. It returns the number
+// of entries recreated, offering a less destructive recovery from partially
+// written service maps than dropping the inconsistent frontends.
+func (lbmap *LBBPFMap) RepairMissingRevNat() (repaired int, err error) {
+	if err := lbmap.checkWritable(); err != nil {
+		return 0, err
+	}
+	existing, err := lbmap.DumpRevNat()
+	if err != nil {
+		return 0, err
+	}
+
+	type revNatEntry struct {
+		key   RevNatKey
+		value RevNatValue
+	}
+	var missing []revNatEntry
+
+	parse := func(key bpf.MapKey, value bpf.MapValue) {
+		svcKey := key.(ServiceKey).ToHost()
+		// Only master entries carry the authoritative frontend address.
+		if svcKey.GetBackendSlot() != 0 {
+			return
+		}
+		svcValue := value.(ServiceValue).ToHost()
+		id := uint16(svcValue.GetRevNat())
+		if id == 0 {
+			return
+		}
+		if _, found := existing[id]; found {
+			return
+		}
+		// Frontends of both scopes share the revNAT ID, repair it once.
+		existing[id] = loadbalancer.L3n4Addr{}
+		missing = append(missing, revNatEntry{key: svcValue.RevNatKey(), value: svcKey.RevNatValue()})
 	}
 
-	m := SourceRange4Map
-	if ipv6 {
-		m = SourceRange6Map
+	if lbmap.ipv4 {
+		if err := Service4MapV2.DumpWithCallback(parse); err != nil {
+			return repaired, fmt.Errorf("dumping %s: %w", Service4MapV2.Name(), err)
+		}
 	}
-	if err := m.DumpWithCallback(parser); err != nil {
-		return nil, err
+	if lbmap.ipv6 {
+		if err := Service6MapV2.DumpWithCallback(parse); err != nil {
+			return repaired, fmt.Errorf("dumping %s: %w", Service6MapV2.Name(), err)
+		}
 	}
 
-	return ret, nil
+	for _, e := range missing {
+		if err := updateRevNatLocked(e.key, e.value); err != nil {
+			return repaired, fmt.Errorf("recreating revNAT entry %+v: %w", e.key, err)
+		}
+		repaired++
+	}
+
+	return repaired, nil
 }
 
 func updateRevNatLocked(key RevNatKey, value RevNatValue) error {
@@ -384,6 +1151,7 @@ func updateRevNatLocked(key RevNatKey, value RevNatValue) error {
 		return err
 	}
 
+	defer observeUpdateDuration(updateMetricRevNat, time.Now())
 	return key.Map().Update(key.ToNetwork(), value.ToNetwork())
 }
 
@@ -391,8 +1159,48 @@ func deleteRevNatLocked(key RevNatKey) error {
 	return key.Map().Delete(key.ToNetwork())
 }
 
-func (*LBBPFMap) UpdateSourceRanges(revNATID uint16, prevSourceRanges []*cidr.CIDR,
-	sourceRanges []*cidr.CIDR, ipv6 bool) error {
+// checkRevNatID returns an error when the given service ID does not fit the
+// 16-bit revNAT key used by the datapath maps. The caller-facing ID space is
+// 32 bits wide, so IDs beyond 16 bits must be rejected explicitly instead of
+// being silently truncated on the uint16 casts below.
+func checkRevNatID(id loadbalancer.ID) error {
+	if id > math.MaxUint16 {
+		return fmt.Errorf("service ID %d exceeds the 16-bit revNAT key space", id)
+	}
+	return nil
+}
+
+// MaxSessionAffinityTimeoutSec is the largest session affinity timeout, in
+// seconds, accepted for a service. The datapath stores the timeout in a full
+// 32-bit field, but the Kubernetes API caps ClientIP TimeoutSeconds at one
+// day, so anything larger indicates a bogus translation rather than a valid
+// configuration.
+const MaxSessionAffinityTimeoutSec = 86400
+
+// ValidateSessionAffinityTimeout returns an error when the given session
+// affinity timeout cannot be programmed for a service with session affinity
+// enabled. Callers should reject invalid timeouts at the API boundary rather
+// than failing late during the BPF map write.
+func ValidateSessionAffinityTimeout(sec uint32) error {
+	if sec == 0 {
+		return fmt.Errorf("session affinity timeout must be a positive number of seconds")
+	}
+	if sec > MaxSessionAffinityTimeoutSec {
+		return fmt.Errorf("session affinity timeout %d exceeds the maximum of %d seconds", sec, MaxSessionAffinityTimeoutSec)
+	}
+	return nil
+}
+
+// UpdateSourceRanges diffs prevSourceRanges against sourceRanges and applies
+// the result to the source range map of the given family. It returns the CIDRs
+// which were actually added to and removed from the map, so that callers can
+// report the effective change. On error, the returned slices describe the
+// entries applied up to that point.
+func (lbmap *LBBPFMap) UpdateSourceRanges(revNATID uint16, prevSourceRanges []*cidr.CIDR,
+	sourceRanges []*cidr.CIDR, ipv6 bool) (added, removed []*cidr.CIDR, err error) {
+	if err := lbmap.checkWritable(); err != nil {
+		return nil, nil, err
+	}
 
 	m := SourceRange4Map
 	if ipv6 {
@@ -415,24 +1223,136 @@ func (*LBBPFMap) UpdateSourceRanges(revNATID uint16, prevSourceRanges []*cidr.CI
 	for _, prevCIDR := range prevSourceRanges {
 		if _, found := srcRangeMap[prevCIDR.String()]; !found {
 			if err := m.Delete(srcRangeKey(prevCIDR, revNATID, ipv6)); err != nil {
-				return err
+				return added, removed, err
 			}
+			removed = append(removed, prevCIDR)
 		} else {
 			delete(srcRangeMap, prevCIDR.String())
 		}
 	}
 
 	for _, cidr := range srcRangeMap {
-		if err := m.Update(srcRangeKey(cidr, revNATID, ipv6), &SourceRangeValue{}); err != nil {
-			return err
+		start := time.Now()
+		err := m.Update(srcRangeKey(cidr, revNATID, ipv6), &SourceRangeValue{})
+		observeUpdateDuration(updateMetricSourceRange, start)
+		if err != nil {
+			return added, removed, err
 		}
+		added = append(added, cidr)
 	}
 
-	return nil
+	return added, removed, nil
+}
+
+// ErrServiceNotFound is returned by GetService when no master key exists for
+// the given frontend.
+var ErrServiceNotFound = errors.New("service not found")
+
+// GetService returns the full service entry for the given frontend by looking
+// up the master key and walking the backend slots of just that service,
+// avoiding a full table dump for single-service inspection. Returns an error
+// wrapping ErrServiceNotFound when the master key is absent.
+func (*LBBPFMap) GetService(fe loadbalancer.L3n4Addr) (*loadbalancer.SVC, error) {
+	var svcKey ServiceKey
+
+	ipv6 := fe.IsIPv6()
+	if ipv6 {
+		svcKey = NewService6Key(fe.AddrCluster.AsNetIP(), fe.Port, u8proto.ANY, fe.Scope, 0)
+	} else {
+		svcKey = NewService4Key(fe.AddrCluster.AsNetIP(), fe.Port, u8proto.ANY, fe.Scope, 0)
+	}
+
+	v, err := svcKey.Map().Lookup(svcKey.ToNetwork())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, fe.String())
+	}
+	masterValue := v.(ServiceValue).ToHost()
+	flags := loadbalancer.ServiceFlags(masterValue.GetFlags())
+
+	svc := &loadbalancer.SVC{
+		Frontend: loadbalancer.L3n4AddrID{
+			L3n4Addr: fe,
+			ID:       loadbalancer.ID(masterValue.GetRevNat()),
+		},
+		Type:             flags.SVCType(),
+		ExtTrafficPolicy: flags.SVCExtTrafficPolicy(),
+		IntTrafficPolicy: flags.SVCIntTrafficPolicy(),
+		NatPolicy:        flags.SVCNatPolicy(fe),
+	}
+
+	for slot := 1; ; slot++ {
+		svcKey.SetBackendSlot(slot)
+		sv, err := svcKey.Map().Lookup(svcKey.ToNetwork())
+		if err != nil {
+			// The slots of a service are contiguous, the first missing
+			// one terminates the walk.
+			break
+		}
+		backendID := sv.(ServiceValue).ToHost().GetBackendID()
+
+		var backendKey BackendKey
+		if ipv6 {
+			backendKey = NewBackend6KeyV3(backendID)
+		} else {
+			backendKey = NewBackend4KeyV3(backendID)
+		}
+		bv, err := backendKey.Map().Lookup(backendKey)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to lookup backend %d of service %s: %w", backendID, fe.String(), err)
+		}
+		svc.Backends = append(svc.Backends, svcBackend(backendID, bv.(BackendValue).ToHost()))
+	}
+
+	return svc, nil
 }
 
 // DumpServiceMaps dumps the services from the BPF maps.
-func (*LBBPFMap) DumpServiceMaps() ([]*loadbalancer.SVC, []error) {
+func (lbmap *LBBPFMap) DumpServiceMaps() ([]*loadbalancer.SVC, []error) {
+	return lbmap.dumpServiceMaps(nil)
+}
+
+// DumpServiceMapsByType dumps only the services matching one of the given
+// types. The filter is applied against the flags of the master service
+// entries before the per-service objects are built, so dumping e.g. only
+// LoadBalancer services stays cheap on clusters with large ClusterIP tables.
+func (lbmap *LBBPFMap) DumpServiceMapsByType(types ...loadbalancer.SVCType) ([]*loadbalancer.SVC, []error) {
+	typeFilter := make(map[loadbalancer.SVCType]struct{}, len(types))
+	for _, t := range types {
+		typeFilter[t] = struct{}{}
+	}
+	return lbmap.dumpServiceMaps(typeFilter)
+}
+
+// dumpServiceMaps dumps the service maps, restricted to the given service
+// types. A nil typeFilter dumps all services.
+func (lbmap *LBBPFMap) dumpServiceMaps(typeFilter map[loadbalancer.SVCType]struct{}) ([]*loadbalancer.SVC, []error) {
+	var newSVCList []*loadbalancer.SVC
+	errs, _ := lbmap.forEachService(typeFilter, func(svc *loadbalancer.SVC) error {
+		newSVCList = append(newSVCList, svc)
+		return nil
+	})
+	return newSVCList, errs
+}
+
+// DumpServiceMapsCallback assembles the services like DumpServiceMaps but
+// hands each one to fn as it is finalized instead of collecting them all,
+// stopping at the first error returned by fn. The per-frontend grouping is
+// still built while the maps are walked, but the per-service objects are not
+// accumulated, reducing peak memory when feeding a streaming API on very
+// large service tables. Map dump errors are joined into the returned error.
+func (lbmap *LBBPFMap) DumpServiceMapsCallback(fn func(*loadbalancer.SVC) error) error {
+	errs, fnErr := lbmap.forEachService(nil, fn)
+	if fnErr != nil {
+		return fnErr
+	}
+	return errors.Join(errs...)
+}
+
+// forEachService walks the service and backend maps, assembles the
+// per-service objects matching the typeFilter and hands each to emit. The
+// errors encountered while dumping the maps are collected and returned
+// separately from the first error returned by emit, which stops the walk.
+func (lbmap *LBBPFMap) forEachService(typeFilter map[loadbalancer.SVCType]struct{}, emit func(*loadbalancer.SVC) error) ([]error, error) {
 	newSVCMap := svcMap{}
 	errors := []error{}
 	flagsCache := map[string]loadbalancer.ServiceFlags{}
@@ -474,7 +1394,7 @@ func (*LBBPFMap) DumpServiceMaps() ([]*loadbalancer.SVC, []error) {
 		newSVCMap.addFEnBE(fe, be, svcKey.GetBackendSlot())
 	}
 
-	if option.Config.EnableIPv4 {
+	if lbmap.ipv4 {
 		// TODO(brb) optimization: instead of dumping the backend map, we can
 		// pass its content to the function.
 		err := Backend4MapV3.DumpWithCallback(parseBackendEntries)
@@ -487,7 +1407,7 @@ func (*LBBPFMap) DumpServiceMaps() ([]*loadbalancer.SVC, []error) {
 		}
 	}
 
-	if option.Config.EnableIPv6 {
+	if lbmap.ipv6 {
 		// TODO(brb) same ^^ optimization applies here as well.
 		err := Backend6MapV3.DumpWithCallback(parseBackendEntries)
 		if err != nil {
@@ -499,49 +1419,166 @@ func (*LBBPFMap) DumpServiceMaps() ([]*loadbalancer.SVC, []error) {
 		}
 	}
 
-	newSVCList := make([]*loadbalancer.SVC, 0, len(newSVCMap))
 	for hash := range newSVCMap {
 		svc := newSVCMap[hash]
 		addrStr := svc.Frontend.AddrCluster.String()
 		portStr := strconv.Itoa(int(svc.Frontend.Port))
 		host := net.JoinHostPort(addrStr, portStr)
-		svc.Type = flagsCache[host].SVCType()
-		svc.ExtTrafficPolicy = flagsCache[host].SVCExtTrafficPolicy()
-		svc.IntTrafficPolicy = flagsCache[host].SVCIntTrafficPolicy()
-		svc.NatPolicy = flagsCache[host].SVCNatPolicy(svc.Frontend.L3n4Addr)
-		newSVCList = append(newSVCList, &svc)
+		flags := flagsCache[host]
+		if typeFilter != nil {
+			if _, wanted := typeFilter[flags.SVCType()]; !wanted {
+				continue
+			}
+		}
+		svc.Type = flags.SVCType()
+		svc.ExtTrafficPolicy = flags.SVCExtTrafficPolicy()
+		svc.IntTrafficPolicy = flags.SVCIntTrafficPolicy()
+		svc.NatPolicy = flags.SVCNatPolicy(svc.Frontend.L3n4Addr)
+		if err := emit(&svc); err != nil {
+			return errors, err
+		}
 	}
 
-	return newSVCList, errors
+	return errors, nil
 }
 
-// DumpBackendMaps dumps the backend entries from the BPF maps.
-func (*LBBPFMap) DumpBackendMaps() ([]*loadbalancer.Backend, error) {
-	backendValueMap := map[loadbalancer.BackendID]BackendValue{}
-	lbBackends := []*loadbalancer.Backend{}
+// DumpServiceMapsReadOnly dumps the services like DumpServiceMaps and
+// additionally cross-checks every frontend against the reverse NAT maps,
+// returning the frontends whose revNAT entry is missing or points at a
+// different address. No map entries are modified or deleted, which makes it
+// safe for diagnostic tools; what to do about the inconsistent keys is left
+// to the caller.
+func (lbmap *LBBPFMap) DumpServiceMapsReadOnly() ([]*loadbalancer.SVC, []loadbalancer.L3n4Addr, []error) {
+	svcs, errors := lbmap.dumpServiceMaps(nil)
 
-	parseBackendEntries := func(key bpf.MapKey, value bpf.MapValue) {
-		// No need to deep copy the key because we are using the ID which
-		// is a value.
-		backendKey := key.(BackendKey)
-		backendValue := value.(BackendValue).ToHost()
-		backendValueMap[backendKey.GetID()] = backendValue
+	revNat, err := lbmap.DumpRevNat()
+	if err != nil {
+		return svcs, nil, append(errors, err)
+	}
+
+	var inconsistent []loadbalancer.L3n4Addr
+	for _, svc := range svcs {
+		if !frontendMatchesRevNat(svc.Frontend, revNat) {
+			inconsistent = append(inconsistent, svc.Frontend.L3n4Addr)
+		}
+	}
+
+	return svcs, inconsistent, errors
+}
+
+// frontendMatchesRevNat reports whether the revNAT entry for the frontend's
+// ID exists and translates back to the frontend's address and port. Scope and
+// protocol are not compared as the revNAT maps do not carry them.
+func frontendMatchesRevNat(fe loadbalancer.L3n4AddrID, revNat map[uint16]loadbalancer.L3n4Addr) bool {
+	rev, ok := revNat[uint16(fe.ID)]
+	if !ok {
+		return false
+	}
+	return rev.AddrCluster.Equal(fe.AddrCluster) && rev.Port == fe.Port
+}
+
+// ServiceEntryHash computes a stable hash over a service's frontend, flags
+// and backend set. The backends are folded in sorted by ID, so the hash is
+// independent of the slot ordering. Only fields stored in the BPF maps are
+// covered (frontend address and revNAT ID, service type and traffic policies,
+// backend addresses, states and zones); in particular the service name is
+// excluded, so the hash of the control-plane's desired state compares equal
+// to the hash of the same service read back from the maps.
+func ServiceEntryHash(svc *loadbalancer.SVC) string {
+	h := sha256.New()
+
+	io.WriteString(h, svc.Frontend.L3n4Addr.Hash())
+	fmt.Fprintf(h, "|%d|%s|%s|%s|%s",
+		svc.Frontend.ID, svc.Type, svc.ExtTrafficPolicy, svc.IntTrafficPolicy, svc.NatPolicy)
+
+	backends := make([]*loadbalancer.Backend, len(svc.Backends))
+	copy(backends, svc.Backends)
+	sort.Slice(backends, func(i, j int) bool { return backends[i].ID < backends[j].ID })
+	for _, be := range backends {
+		fmt.Fprintf(h, "|%d|%s|%d|%d", be.ID, be.L3n4Addr.Hash(), be.State, be.ZoneID)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DumpServiceHashes dumps the service maps and returns the ServiceEntryHash
+// of each service, keyed by the frontend's string representation. The service
+// manager can compare these against the hashes of its desired state to find
+// entries which drifted, e.g. due to out-of-band map edits, without building
+// and diffing the full per-service objects.
+func (lbmap *LBBPFMap) DumpServiceHashes() (map[string]string, error) {
+	svcs, errs := lbmap.dumpServiceMaps(nil)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	hashes := make(map[string]string, len(svcs))
+	for _, svc := range svcs {
+		hashes[svc.Frontend.L3n4Addr.String()] = ServiceEntryHash(svc)
 	}
 
+	return hashes, nil
+}
+
+// DumpRevNat dumps the reverse NAT maps and returns the frontend address
+// each revNAT id maps back to. It allows answering "which service owns revNAT
+// id X" without dumping the whole service table, e.g. when debugging SNAT/DSR
+// issues.
+func (*LBBPFMap) DumpRevNat() (map[uint16]loadbalancer.L3n4Addr, error) {
+	out := map[uint16]loadbalancer.L3n4Addr{}
+
 	if option.Config.EnableIPv4 {
-		err := Backend4MapV3.DumpWithCallback(parseBackendEntries)
-		if err != nil {
-			return nil, fmt.Errorf("Unable to dump lb4 backends map: %w", err)
+		parse := func(key bpf.MapKey, value bpf.MapValue) {
+			k := key.(*RevNat4Key).ToHost().(*RevNat4Key)
+			v := value.(*RevNat4Value).ToHost().(*RevNat4Value)
+			addrCluster := cmtypes.MustAddrClusterFromIP(v.Address.IP())
+			out[k.Key] = *loadbalancer.NewL3n4Addr(loadbalancer.NONE, addrCluster, v.Port, loadbalancer.ScopeExternal)
+		}
+		if err := RevNat4Map.DumpWithCallback(parse); err != nil {
+			return nil, fmt.Errorf("Unable to dump lb4 reverse NAT map: %w", err)
 		}
 	}
 
 	if option.Config.EnableIPv6 {
-		err := Backend6MapV3.DumpWithCallback(parseBackendEntries)
-		if err != nil {
-			return nil, fmt.Errorf("Unable to dump lb6 backends map: %w", err)
+		parse := func(key bpf.MapKey, value bpf.MapValue) {
+			k := key.(*RevNat6Key).ToHost().(*RevNat6Key)
+			v := value.(*RevNat6Value).ToHost().(*RevNat6Value)
+			addrCluster := cmtypes.MustAddrClusterFromIP(v.Address.IP())
+			out[k.Key] = *loadbalancer.NewL3n4Addr(loadbalancer.NONE, addrCluster, v.Port, loadbalancer.ScopeExternal)
+		}
+		if err := RevNat6Map.DumpWithCallback(parse); err != nil {
+			return nil, fmt.Errorf("Unable to dump lb6 reverse NAT map: %w", err)
 		}
 	}
 
+	return out, nil
+}
+
+// BackendMapVersion selects the backend map key/value format to dump.
+type BackendMapVersion int
+
+const (
+	// BackendMapV2 selects the legacy v2 backend maps (cilium_lb{4,6}_backends_v2).
+	BackendMapV2 BackendMapVersion = 2
+	// BackendMapV3 selects the current v3 backend maps (cilium_lb{4,6}_backends_v3).
+	BackendMapV3 BackendMapVersion = 3
+)
+
+// DumpBackendMaps dumps the backend entries from the BPF maps.
+func (lbmap *LBBPFMap) DumpBackendMaps() ([]*loadbalancer.Backend, error) {
+	return lbmap.DumpBackendMapsVersioned(BackendMapV3)
+}
+
+// DumpBackendMapsVersioned dumps the backend entries from the BPF maps with
+// the given key/value version. Dumping the legacy v2 maps allows comparing
+// old and new backend maps during an upgrade, before the v2 maps are removed.
+func (lbmap *LBBPFMap) DumpBackendMapsVersioned(v BackendMapVersion) ([]*loadbalancer.Backend, error) {
+	backendValueMap, err := lbmap.dumpBackendValues(v)
+	if err != nil {
+		return nil, err
+	}
+
+	lbBackends := make([]*loadbalancer.Backend, 0, len(backendValueMap))
 	for backendID, backendVal := range backendValueMap {
 		ip := backendVal.GetAddress()
 		addrCluster := cmtypes.MustAddrClusterFromIP(ip)
@@ -556,13 +1593,131 @@ func (*LBBPFMap) DumpBackendMaps() ([]*loadbalancer.Backend, error) {
 	return lbBackends, nil
 }
 
+// DumpBackendStateCounts tallies the backends of the enabled address families
+// by state in a single dump pass, without materializing per-backend objects.
+// It backs status output showing how many backends are active, quarantined,
+// terminating or in maintenance.
+func (lbmap *LBBPFMap) DumpBackendStateCounts() (map[loadbalancer.BackendState]int, error) {
+	backendValueMap, err := lbmap.dumpBackendValues(BackendMapV3)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[loadbalancer.BackendState]int{}
+	for _, backendVal := range backendValueMap {
+		counts[loadbalancer.GetBackendStateFromFlags(backendVal.GetFlags())]++
+	}
+
+	return counts, nil
+}
+
+// dumpBackendValues dumps the backend maps of the enabled address families
+// with the given version into a map keyed by backend ID. It is the shared
+// dump pass behind DumpBackendMapsVersioned and DumpBackendStateCounts.
+func (lbmap *LBBPFMap) dumpBackendValues(v BackendMapVersion) (map[loadbalancer.BackendID]BackendValue, error) {
+	backendValueMap := map[loadbalancer.BackendID]BackendValue{}
+
+	parseBackendEntries := func(key bpf.MapKey, value bpf.MapValue) {
+		// No need to deep copy the key because we are using the ID which
+		// is a value.
+		backendKey := key.(BackendKey)
+		backendValue := value.(BackendValue).ToHost()
+		backendValueMap[backendKey.GetID()] = backendValue
+	}
+
+	var backend4Map, backend6Map *bpf.Map
+	switch v {
+	case BackendMapV2:
+		backend4Map, backend6Map = Backend4MapV2, Backend6MapV2
+	case BackendMapV3:
+		backend4Map, backend6Map = Backend4MapV3, Backend6MapV3
+	default:
+		return nil, fmt.Errorf("unsupported backend map version %d", v)
+	}
+
+	if lbmap.ipv4 {
+		err := backend4Map.DumpWithCallback(parseBackendEntries)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to dump lb4 backends map (v%d): %w", v, err)
+		}
+	}
+
+	if lbmap.ipv6 {
+		err := backend6Map.DumpWithCallback(parseBackendEntries)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to dump lb6 backends map (v%d): %w", v, err)
+		}
+	}
+
+	return backendValueMap, nil
+}
+
+// MaglevRecreatedStatus returns for both address families whether the maglev
+// lookup BPF map was recreated due to the changed M param without the
+// recreation having been acknowledged via AcknowledgeMaglevRecreated yet.
+// Both flags are read under a single lock acquisition, so the pair is a
+// consistent snapshot.
+func (*LBBPFMap) MaglevRecreatedStatus() (v4, v6 bool) {
+	maglevRecreatedMu.RLock()
+	defer maglevRecreatedMu.RUnlock()
+	return maglevRecreatedIPv4, maglevRecreatedIPv6
+}
+
 // IsMaglevLookupTableRecreated returns true if the maglev lookup BPF map
-// was recreated due to the changed M param.
-func (*LBBPFMap) IsMaglevLookupTableRecreated(ipv6 bool) bool {
+// was recreated due to the changed M param and the recreation has not yet
+// been acknowledged via AcknowledgeMaglevRecreated.
+func (lbmap *LBBPFMap) IsMaglevLookupTableRecreated(ipv6 bool) bool {
+	v4, v6 := lbmap.MaglevRecreatedStatus()
+	if ipv6 {
+		return v6
+	}
+	return v4
+}
+
+// AcknowledgeMaglevRecreated resets the maglev-recreated flag for the given
+// family. It is called once the service manager has reacted to the recreated
+// lookup table, i.e. re-pushed all maglev tables, so that a later query only
+// reflects subsequent reinitializations.
+func (*LBBPFMap) AcknowledgeMaglevRecreated(ipv6 bool) {
+	maglevRecreatedMu.Lock()
+	defer maglevRecreatedMu.Unlock()
 	if ipv6 {
-		return maglevRecreatedIPv6
+		maglevRecreatedIPv6 = false
+	} else {
+		maglevRecreatedIPv4 = false
+	}
+}
+
+var (
+	// l7ProxyPortsMutex protects l7ProxyPorts.
+	l7ProxyPortsMutex lock.Mutex
+	// l7ProxyPorts tracks the L7 proxy port assigned per service (keyed by
+	// revNAT ID), to detect two services being handed the same proxy port.
+	l7ProxyPorts = map[int]uint16{}
+)
+
+// registerL7ProxyPort records the L7 proxy port assignment of the given
+// service and reports whether the port is already assigned to a different
+// service. Such a conflict indicates a bug in the L7 LB orchestration and
+// typically causes traffic misrouting.
+func registerL7ProxyPort(proxyPort uint16, revNATID int) (conflictingID int, conflict bool) {
+	l7ProxyPortsMutex.Lock()
+	defer l7ProxyPortsMutex.Unlock()
+
+	for id, port := range l7ProxyPorts {
+		if port == proxyPort && id != revNATID {
+			return id, true
+		}
 	}
-	return maglevRecreatedIPv4
+	l7ProxyPorts[revNATID] = proxyPort
+	return 0, false
+}
+
+// releaseL7ProxyPort drops the L7 proxy port assignment of the given service.
+func releaseL7ProxyPort(revNATID int) {
+	l7ProxyPortsMutex.Lock()
+	defer l7ProxyPortsMutex.Unlock()
+	delete(l7ProxyPorts, revNATID)
 }
 
 func updateMasterService(fe ServiceKey, v ServiceValue, activeBackends int, revNATID int, svcType loadbalancer.SVCType,
@@ -573,6 +1728,24 @@ func updateMasterService(fe ServiceKey, v ServiceValue, activeBackends int, revN
 	isRoutable := !fe.IsSurrogate() &&
 		(svcType != loadbalancer.SVCTypeClusterIP || option.Config.ExternalClusterIP)
 
+	if l7lbProxyPort != 0 {
+		if otherID, conflict := registerL7ProxyPort(l7lbProxyPort, revNATID); conflict {
+			log.WithFields(logrus.Fields{
+				logfields.L7LBProxyPort: l7lbProxyPort,
+				logfields.ServiceID:     revNATID,
+				"conflictingServiceID":  otherID,
+			}).Warn("L7 proxy port is already assigned to another service, traffic may be misrouted")
+		}
+	} else {
+		releaseL7ProxyPort(revNATID)
+	}
+
+	extTrafficPolicy := loadbalancer.SVCTrafficPolicyCluster
+	if svcExtLocal {
+		extTrafficPolicy = loadbalancer.SVCTrafficPolicyLocal
+	}
+	metrics.ServiceBackendsCount.WithLabelValues(string(svcType), string(extTrafficPolicy)).Set(float64(activeBackends))
+
 	fe.SetBackendSlot(0)
 	v.SetCount(activeBackends)
 	v.SetRevNat(revNATID)
@@ -589,6 +1762,9 @@ func updateMasterService(fe ServiceKey, v ServiceValue, activeBackends int, revN
 	})
 	v.SetFlags(flag.UInt16())
 	if sessionAffinity {
+		if err := ValidateSessionAffinityTimeout(sessionAffinityTimeoutSec); err != nil {
+			return err
+		}
 		v.SetSessionAffinityTimeoutSec(sessionAffinityTimeoutSec)
 	}
 	if l7lbProxyPort != 0 {
@@ -632,6 +1808,7 @@ func updateBackend(backend Backend) error {
 		return err
 	}
 
+	defer observeUpdateDuration(updateMetricBackend, time.Now())
 	return backend.Map().Update(backend.GetKey(), backend.GetValue().ToNetwork())
 }
 
@@ -648,7 +1825,10 @@ func updateServiceEndpoint(key ServiceKey, value ServiceValue) error {
 		return err
 	}
 
-	if err := key.Map().Update(key.ToNetwork(), value.ToNetwork()); err != nil {
+	start := time.Now()
+	err := key.Map().Update(key.ToNetwork(), value.ToNetwork())
+	observeUpdateDuration(updateMetricService, start)
+	if err != nil {
 		return err
 	}
 
@@ -717,8 +1897,10 @@ func (svcs svcMap) addFEnBE(fe *loadbalancer.L3n4AddrID, be *loadbalancer.Backen
 }
 
 // Init updates the map info defaults for sock rev nat {4,6} and LB maps and
-// then initializes all LB-related maps.
-func Init(params InitParams) {
+// then initializes all LB-related maps. It returns an error naming the group
+// of maps which failed to initialize, so that agent startup can fail fast
+// with a clear message instead of panicking deep inside a map operation.
+func Init(params InitParams) error {
 	if params.MaxSockRevNatMapEntries != 0 {
 		MaxSockRevNat4MapEntries = params.MaxSockRevNatMapEntries
 		MaxSockRevNat6MapEntries = params.MaxSockRevNatMapEntries
@@ -726,9 +1908,19 @@ func Init(params InitParams) {
 
 	MaglevMapMaxEntries = params.MaglevMapMaxEntries
 
-	initSVC(params)
-	initAffinity(params)
-	initSourceRange(params)
+	if err := initSVC(params); err != nil {
+		return fmt.Errorf("initializing service-related maps: %w", err)
+	}
+
+	if err := initAffinity(params); err != nil {
+		return fmt.Errorf("initializing affinity maps: %w", err)
+	}
+
+	if err := initSourceRange(params); err != nil {
+		return fmt.Errorf("initializing source range maps: %w", err)
+	}
+
+	return nil
 }
 
 // ExistsSockRevNat checks if the passed entry exists in the sock rev nat map.
@@ -748,6 +1940,205 @@ func (*LBBPFMap) ExistsSockRevNat(cookie uint64, addr net.IP, port uint16) bool
 	return false
 }
 
+// DeleteAllServices removes every entry (master and backend slots alike) from
+// the service maps of the enabled address families, along with the associated
+// reverse NAT entries. It backs a full LB state flush, e.g. "cilium bpf lb"
+// cleanup in integration tests. Per-map errors are aggregated instead of
+// aborting on the first, and a summary count is logged.
+func (lbmap *LBBPFMap) DeleteAllServices() error {
+	if err := lbmap.checkWritable(); err != nil {
+		return err
+	}
+	var errs error
+	deleted := 0
+
+	flush := func(m *bpf.Map, name string) {
+		count := 0
+		if err := m.DumpWithCallback(func(bpf.MapKey, bpf.MapValue) { count++ }); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("unable to dump %s map: %w", name, err))
+		}
+		if err := m.DeleteAll(); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("unable to flush %s map: %w", name, err))
+			return
+		}
+		deleted += count
+	}
+
+	if lbmap.ipv4 {
+		flush(Service4MapV2, "lb4 services")
+		flush(RevNat4Map, "lb4 reverse NAT")
+	}
+	if lbmap.ipv6 {
+		flush(Service6MapV2, "lb6 services")
+		flush(RevNat6Map, "lb6 reverse NAT")
+	}
+
+	// All proxy port assignments refer to deleted services now.
+	l7ProxyPortsMutex.Lock()
+	l7ProxyPorts = map[int]uint16{}
+	l7ProxyPortsMutex.Unlock()
+
+	log.WithField("deletedEntries", deleted).Info("Flushed all service and reverse NAT map entries")
+
+	return errs
+}
+
+// SockRevNatOccupancy counts the entries of the socket reverse NAT maps per
+// address family and publishes them as gauge metrics. The maps are sized by
+// MaxSockRevNat4MapEntries/MaxSockRevNat6MapEntries, so the counts tell
+// operators tuning socket LB sizing how close they are to the configured max.
+// Disabled families report a zero count.
+func (lbmap *LBBPFMap) SockRevNatOccupancy() (v4count, v6count int, err error) {
+	count := func(m *bpf.Map) (int, error) {
+		n := 0
+		if err := m.DumpWithCallback(func(bpf.MapKey, bpf.MapValue) { n++ }); err != nil {
+			return 0, err
+		}
+		return n, nil
+	}
+
+	if lbmap.ipv4 {
+		v4count, err = count(SockRevNat4Map)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Unable to dump lb4 sock rev nat map: %w", err)
+		}
+	}
+	if lbmap.ipv6 {
+		v6count, err = count(SockRevNat6Map)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Unable to dump lb6 sock rev nat map: %w", err)
+		}
+	}
+
+	metrics.SockRevNatEntries.WithLabelValues("ipv4").Set(float64(v4count))
+	metrics.SockRevNatEntries.WithLabelValues("ipv6").Set(float64(v6count))
+
+	return v4count, v6count, nil
+}
+
+// PreflightCapacity checks whether the configured service and backend map
+// sizes can accommodate the given number of additional entries on top of the
+// current occupancy. It is meant to be called before bulk upserts, e.g. the
+// restore path, so that an undersized map is reported with resize guidance
+// up front instead of surfacing as E2BIG halfway through the writes.
+//
+// The check is conservative: entries of both address families count against
+// the per-family map size, assuming the worst case of all new entries landing
+// in a single family.
+func (lbmap *LBBPFMap) PreflightCapacity(serviceEntries, backendEntries int) error {
+	count := func(m *bpf.Map) (int, error) {
+		n := 0
+		if err := m.DumpWithCallback(func(bpf.MapKey, bpf.MapValue) { n++ }); err != nil {
+			return 0, fmt.Errorf("Unable to dump %s: %w", m.Name(), err)
+		}
+		return n, nil
+	}
+
+	check := func(m *bpf.Map, intended, maxEntries int, resizeFlag string) error {
+		occupied, err := count(m)
+		if err != nil {
+			return err
+		}
+		if occupied+intended > maxEntries {
+			return fmt.Errorf("map %s cannot fit %d additional entries (%d occupied, %d max), "+
+				"increase --%s to at least %d",
+				m.Name(), intended, occupied, maxEntries, resizeFlag, occupied+intended)
+		}
+		return nil
+	}
+
+	if lbmap.ipv4 {
+		if err := check(Service4MapV2, serviceEntries, ServiceMapMaxEntries, option.LBServiceMapMaxEntries); err != nil {
+			return err
+		}
+		if err := check(Backend4MapV3, backendEntries, ServiceBackEndMapMaxEntries, option.LBBackendMapMaxEntries); err != nil {
+			return err
+		}
+	}
+	if lbmap.ipv6 {
+		if err := check(Service6MapV2, serviceEntries, ServiceMapMaxEntries, option.LBServiceMapMaxEntries); err != nil {
+			return err
+		}
+		if err := check(Backend6MapV3, backendEntries, ServiceBackEndMapMaxEntries, option.LBBackendMapMaxEntries); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MapOccupancy describes the fill state of a single load balancing BPF map.
+type MapOccupancy struct {
+	// Entries is the number of entries currently in the map.
+	Entries int
+	// MaxEntries is the configured capacity of the map.
+	MaxEntries int
+}
+
+// Occupancy reports the current entry count and the configured capacity of
+// every initialized load balancing BPF map, keyed by map name, and publishes
+// them as the cilium_lb_map_entries and cilium_lb_map_max_entries gauges. It
+// gives operators tuning the LB map size options a single view of how full
+// each map is; the resize guidance issued on E2BIG refers to these numbers.
+// Maps of a disabled address family or feature are omitted. Per-map dump
+// errors are aggregated, the returned map contains the maps which could be
+// counted.
+func (lbmap *LBBPFMap) Occupancy() (map[string]MapOccupancy, error) {
+	occupancy := map[string]MapOccupancy{}
+	var errs error
+
+	count := func(m *bpf.Map, maxEntries int) {
+		if m == nil {
+			return
+		}
+		n := 0
+		if err := m.DumpWithCallback(func(bpf.MapKey, bpf.MapValue) { n++ }); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("unable to dump %s: %w", m.Name(), err))
+			return
+		}
+		occupancy[m.Name()] = MapOccupancy{Entries: n, MaxEntries: maxEntries}
+	}
+
+	countMaglev := func(m *MaglevOuterMap, name string) {
+		if m == nil {
+			return
+		}
+		n := 0
+		var key MaglevOuterKey
+		var val MaglevOuterVal
+		if err := m.IterateWithCallback(&key, &val, func(_, _ interface{}) { n++ }); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("unable to iterate %s: %w", name, err))
+			return
+		}
+		occupancy[name] = MapOccupancy{Entries: n, MaxEntries: MaglevMapMaxEntries}
+	}
+
+	if lbmap.ipv4 {
+		count(Service4MapV2, ServiceMapMaxEntries)
+		count(Backend4MapV3, ServiceBackEndMapMaxEntries)
+		count(RevNat4Map, RevNatMapMaxEntries)
+		count(Affinity4Map, AffinityMapMaxEntries)
+		count(SourceRange4Map, SourceRangeMapMaxEntries)
+		countMaglev(maglevOuter4Map, MaglevOuter4MapName)
+	}
+	if lbmap.ipv6 {
+		count(Service6MapV2, ServiceMapMaxEntries)
+		count(Backend6MapV3, ServiceBackEndMapMaxEntries)
+		count(RevNat6Map, RevNatMapMaxEntries)
+		count(Affinity6Map, AffinityMapMaxEntries)
+		count(SourceRange6Map, SourceRangeMapMaxEntries)
+		countMaglev(maglevOuter6Map, MaglevOuter6MapName)
+	}
+	count(AffinityMatchMap, AffinityMapMaxEntries)
+
+	for name, occ := range occupancy {
+		metrics.LBMapEntries.WithLabelValues(name).Set(float64(occ.Entries))
+		metrics.LBMapMaxEntries.WithLabelValues(name).Set(float64(occ.MaxEntries))
+	}
+
+	return occupancy, errs
+}
+
 // InitParams represents the parameters to be passed to Init().
 type InitParams struct {
 	IPv4, IPv6 bool
@@ -757,4 +2148,17 @@ type InitParams struct {
 	AffinityMapMaxEntries                                           int
 	SourceRangeMapMaxEntries                                        int
 	MaglevMapMaxEntries                                             int
+
+	// PinPrefix, if non-empty, is prepended to the names of all maps
+	// created by Init and thereby to their bpffs pin paths. Tests and
+	// tools can use it to create an LB map set isolated from the agent's
+	// maps. The maps remain the package-level vars which all helpers
+	// consult, so a process operates on one prefix at a time; a new
+	// prefix requires another Init call.
+	PinPrefix string
+}
+
+// pinName returns the given map name with the PinPrefix applied.
+func (p InitParams) pinName(name string) string {
+	return p.PinPrefix + name
 }
@@ -0,0 +1,244 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package lbmap
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cmtypes "github.com/cilium/cilium/pkg/clustermesh/types"
+	datapathTypes "github.com/cilium/cilium/pkg/datapath/types"
+	"github.com/cilium/cilium/pkg/loadbalancer"
+)
+
+func testBackend(id loadbalancer.BackendID, proto loadbalancer.L4Type) *loadbalancer.Backend {
+	return &loadbalancer.Backend{
+		ID: id,
+		L3n4Addr: loadbalancer.L3n4Addr{
+			AddrCluster: cmtypes.MustParseAddrCluster("10.0.0.1"),
+			L4Addr:      loadbalancer.L4Addr{Protocol: proto, Port: 8080},
+		},
+	}
+}
+
+func TestValidateBackendProtocols(t *testing.T) {
+	// A uniform set of backend protocols is accepted, wildcards included.
+	p := &datapathTypes.UpsertServiceParams{
+		ID: 1,
+		ActiveBackends: map[string]*loadbalancer.Backend{
+			"a": testBackend(1, loadbalancer.TCP),
+			"b": testBackend(2, loadbalancer.TCP),
+			"c": testBackend(3, loadbalancer.NONE),
+		},
+	}
+	require.NoError(t, validateBackendProtocols(p))
+
+	// Mixing TCP and UDP backends is rejected, naming the offenders.
+	p.ActiveBackends["d"] = testBackend(4, loadbalancer.UDP)
+	err := validateBackendProtocols(p)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "mixes backend protocols")
+}
+
+func TestRegisterL7ProxyPort(t *testing.T) {
+	defer func() {
+		l7ProxyPortsMutex.Lock()
+		l7ProxyPorts = map[int]uint16{}
+		l7ProxyPortsMutex.Unlock()
+	}()
+
+	// First assignment of a port is conflict-free.
+	_, conflict := registerL7ProxyPort(15000, 1)
+	require.False(t, conflict)
+
+	// Re-registering the same service with the same port is not a conflict.
+	_, conflict = registerL7ProxyPort(15000, 1)
+	require.False(t, conflict)
+
+	// A different service claiming the same port is a conflict.
+	otherID, conflict := registerL7ProxyPort(15000, 2)
+	require.True(t, conflict)
+	require.Equal(t, 1, otherID)
+
+	// A different port is fine.
+	_, conflict = registerL7ProxyPort(15001, 2)
+	require.False(t, conflict)
+
+	// Once the first service releases its port, it can be claimed again.
+	releaseL7ProxyPort(1)
+	_, conflict = registerL7ProxyPort(15000, 3)
+	require.False(t, conflict)
+}
+
+func TestCheckRevNatID(t *testing.T) {
+	// The full 16-bit space is usable.
+	require.NoError(t, checkRevNatID(1))
+	require.NoError(t, checkRevNatID(math.MaxUint16))
+
+	// IDs beyond it are rejected rather than truncated.
+	err := checkRevNatID(math.MaxUint16 + 1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exceeds the 16-bit revNAT key space")
+}
+
+func TestValidateSessionAffinityTimeout(t *testing.T) {
+	// Anything within the Kubernetes ClientIP timeout range is accepted.
+	require.NoError(t, ValidateSessionAffinityTimeout(1))
+	require.NoError(t, ValidateSessionAffinityTimeout(MaxSessionAffinityTimeoutSec))
+
+	// A zero timeout with session affinity enabled is a translation bug.
+	err := ValidateSessionAffinityTimeout(0)
+	require.Error(t, err)
+
+	err = ValidateSessionAffinityTimeout(MaxSessionAffinityTimeoutSec + 1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exceeds the maximum")
+}
+
+func TestValidateNat46Backends(t *testing.T) {
+	mkParams := func(addrs ...string) *datapathTypes.UpsertServiceParams {
+		backends := make(map[string]*loadbalancer.Backend, len(addrs))
+		for i, addr := range addrs {
+			backends[addr] = &loadbalancer.Backend{
+				ID: loadbalancer.BackendID(i + 1),
+				L3n4Addr: loadbalancer.L3n4Addr{
+					AddrCluster: cmtypes.MustParseAddrCluster(addr),
+				},
+			}
+		}
+		return &datapathTypes.UpsertServiceParams{
+			ID:             1,
+			NatPolicy:      loadbalancer.SVCNatPolicyNat46,
+			ActiveBackends: backends,
+		}
+	}
+
+	// Plain IPv4 backends encode to v4-mapped addresses, already mapped
+	// ones are accepted as-is.
+	require.NoError(t, validateNat46Backends(mkParams("10.0.0.1", "::ffff:10.0.0.2")))
+
+	// A plain IPv6 backend under a NAT46 frontend is rejected.
+	err := validateNat46Backends(mkParams("10.0.0.1", "fd00::1"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not a valid IPv4-in-IPv6 address")
+
+	// Services without NAT46 policy are not restricted.
+	p := mkParams("fd00::1")
+	p.NatPolicy = loadbalancer.SVCNatPolicyNone
+	require.NoError(t, validateNat46Backends(p))
+}
+
+func TestFrontendMatchesRevNat(t *testing.T) {
+	fe := loadbalancer.L3n4AddrID{
+		ID: 7,
+		L3n4Addr: loadbalancer.L3n4Addr{
+			AddrCluster: cmtypes.MustParseAddrCluster("10.0.0.1"),
+			L4Addr:      loadbalancer.L4Addr{Protocol: loadbalancer.NONE, Port: 80},
+		},
+	}
+
+	revNat := map[uint16]loadbalancer.L3n4Addr{
+		7: {
+			AddrCluster: cmtypes.MustParseAddrCluster("10.0.0.1"),
+			L4Addr:      loadbalancer.L4Addr{Protocol: loadbalancer.NONE, Port: 80},
+		},
+	}
+	require.True(t, frontendMatchesRevNat(fe, revNat))
+
+	// A missing revNAT entry is inconsistent.
+	require.False(t, frontendMatchesRevNat(fe, map[uint16]loadbalancer.L3n4Addr{}))
+
+	// An entry translating back to a different address or port is inconsistent.
+	revNat[7] = loadbalancer.L3n4Addr{
+		AddrCluster: cmtypes.MustParseAddrCluster("10.0.0.2"),
+		L4Addr:      loadbalancer.L4Addr{Protocol: loadbalancer.NONE, Port: 80},
+	}
+	require.False(t, frontendMatchesRevNat(fe, revNat))
+
+	revNat[7] = loadbalancer.L3n4Addr{
+		AddrCluster: cmtypes.MustParseAddrCluster("10.0.0.1"),
+		L4Addr:      loadbalancer.L4Addr{Protocol: loadbalancer.NONE, Port: 81},
+	}
+	require.False(t, frontendMatchesRevNat(fe, revNat))
+}
+
+func TestReadOnlyRejectsWrites(t *testing.T) {
+	m := &LBBPFMap{ipv4: true, readOnly: true}
+
+	err := m.UpsertService(&datapathTypes.UpsertServiceParams{ID: 1})
+	require.ErrorContains(t, err, "read-only")
+
+	err = m.DeleteAllServices()
+	require.ErrorContains(t, err, "read-only")
+
+	errs := m.DeleteBackendByIDs([]loadbalancer.BackendID{1, 2})
+	require.Len(t, errs, 1)
+	require.ErrorContains(t, errs[0], "read-only")
+
+	_, err = m.RepairMissingRevNat()
+	require.ErrorContains(t, err, "read-only")
+}
+
+func TestServiceEntryHash(t *testing.T) {
+	newSVC := func() *loadbalancer.SVC {
+		return &loadbalancer.SVC{
+			Frontend: loadbalancer.L3n4AddrID{
+				L3n4Addr: loadbalancer.L3n4Addr{
+					AddrCluster: cmtypes.MustParseAddrCluster("10.0.0.10"),
+					L4Addr:      loadbalancer.L4Addr{Protocol: loadbalancer.NONE, Port: 80},
+				},
+				ID: 1,
+			},
+			Type: loadbalancer.SVCTypeClusterIP,
+			Backends: []*loadbalancer.Backend{
+				testBackend(1, loadbalancer.NONE),
+				testBackend(2, loadbalancer.NONE),
+			},
+		}
+	}
+
+	// The hash is stable and independent of the backend ordering.
+	a, b := newSVC(), newSVC()
+	b.Backends[0], b.Backends[1] = b.Backends[1], b.Backends[0]
+	require.Equal(t, ServiceEntryHash(a), ServiceEntryHash(b))
+
+	// Any covered field changing yields a different hash.
+	c := newSVC()
+	c.Backends[0].State = loadbalancer.BackendStateQuarantined
+	require.NotEqual(t, ServiceEntryHash(a), ServiceEntryHash(c))
+
+	d := newSVC()
+	d.Type = loadbalancer.SVCTypeNodePort
+	require.NotEqual(t, ServiceEntryHash(a), ServiceEntryHash(d))
+
+	// The service name is not covered, desired state and dumped map state
+	// compare equal regardless of it.
+	e := newSVC()
+	e.Name = loadbalancer.ServiceName{Namespace: "default", Name: "svc"}
+	require.Equal(t, ServiceEntryHash(a), ServiceEntryHash(e))
+}
+
+func TestInitPinPrefix(t *testing.T) {
+	// Note this only constructs the map objects, nothing is created in the
+	// kernel or pinned until the maps are opened.
+	err := Init(InitParams{
+		IPv4:                     true,
+		IPv6:                     true,
+		ServiceMapMaxEntries:     DefaultMaxEntries,
+		BackEndMapMaxEntries:     DefaultMaxEntries,
+		RevNatMapMaxEntries:      DefaultMaxEntries,
+		AffinityMapMaxEntries:    DefaultMaxEntries,
+		SourceRangeMapMaxEntries: DefaultMaxEntries,
+		MaglevMapMaxEntries:      DefaultMaxEntries,
+		PinPrefix:                "test_",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "test_"+Service4MapV2Name, Service4MapV2.Name())
+	require.Equal(t, "test_"+Backend6MapV3Name, Backend6MapV3.Name())
+	require.Equal(t, "test_"+AffinityMatchMapName, AffinityMatchMap.Name())
+	require.Equal(t, "test_"+SourceRange4MapName, SourceRange4Map.Name())
+}
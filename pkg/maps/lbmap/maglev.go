@@ -7,10 +7,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 
 	"github.com/cilium/cilium/pkg/bpf"
+	"github.com/cilium/cilium/pkg/byteorder"
 	"github.com/cilium/cilium/pkg/ebpf"
 	"github.com/cilium/cilium/pkg/loadbalancer"
+	"github.com/cilium/cilium/pkg/lock"
 )
 
 const (
@@ -21,11 +24,15 @@ const (
 )
 
 var (
-	maglevOuter4Map     *MaglevOuterMap
-	maglevOuter6Map     *MaglevOuterMap
+	maglevOuter4Map *MaglevOuterMap
+	maglevOuter6Map *MaglevOuterMap
+	maglevTableSize uint32
+
+	// maglevRecreatedMu protects the recreated flags against concurrent
+	// initialization and acknowledgement.
+	maglevRecreatedMu   lock.RWMutex
 	maglevRecreatedIPv4 bool
 	maglevRecreatedIPv6 bool
-	maglevTableSize     uint32
 )
 
 // InitMaglevMaps inits the ipv4 and/or ipv6 maglev outer and inner maps.
@@ -36,17 +43,20 @@ func InitMaglevMaps(ipv4, ipv6 bool, tableSize uint32) error {
 	// sizes.
 	// This would confuse the MaybeInitMaglevMaps() function, which would
 	// not be able to figure out the correct table size.
-	r, err := deleteMapIfMNotMatch(MaglevOuter4MapName, tableSize)
+	r4, err := deleteMapIfMNotMatch(MaglevOuter4MapName, tableSize)
 	if err != nil {
 		return err
 	}
-	maglevRecreatedIPv4 = r
 
-	r, err = deleteMapIfMNotMatch(MaglevOuter6MapName, tableSize)
+	r6, err := deleteMapIfMNotMatch(MaglevOuter6MapName, tableSize)
 	if err != nil {
 		return err
 	}
-	maglevRecreatedIPv6 = r
+
+	maglevRecreatedMu.Lock()
+	maglevRecreatedIPv4 = r4
+	maglevRecreatedIPv6 = r6
+	maglevRecreatedMu.Unlock()
 
 	dummyInnerMapSpec := newMaglevInnerMapSpec(tableSize)
 	if ipv4 {
@@ -131,6 +141,38 @@ func updateMaglevTable(ipv6 bool, revNATID uint16, backendIDs []loadbalancer.Bac
 	return nil
 }
 
+// DumpMaglevServices returns the IDs of all services which currently have a
+// Maglev lookup table installed in the outer map of the given protocol.
+// Operators can cross-check the result against the service maps to detect
+// services missing a table or orphaned tables of deleted services, and can
+// combine it with MaglevOuterMap.DumpBackends for a full diagnostic.
+func DumpMaglevServices(ipv6 bool) ([]uint16, error) {
+	outer := maglevOuter4Map
+	if ipv6 {
+		outer = maglevOuter6Map
+	}
+
+	if outer == nil {
+		return nil, errors.New("outer maglev maps not yet initialized")
+	}
+
+	var ids []uint16
+	var key MaglevOuterKey
+	var val MaglevOuterVal
+	iter := outer.Iterate()
+	for iter.Next(&key, &val) {
+		// The key is stored in network byte order in the map.
+		ids = append(ids, byteorder.NetworkToHost16(key.RevNatID))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("iterating outer map: %w", err)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids, nil
+}
+
 // deleteMaglevTable deletes the inner Maglev lookup table for the given service ID.
 func deleteMaglevTable(ipv6 bool, revNATID uint16) error {
 	outerMap := maglevOuter4Map
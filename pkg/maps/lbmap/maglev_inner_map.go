@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"unsafe"
 
+	"github.com/sirupsen/logrus"
+
 	"github.com/cilium/cilium/pkg/ebpf"
 	"github.com/cilium/cilium/pkg/loadbalancer"
 )
@@ -64,6 +66,53 @@ func createMaglevInnerMap(tableSize uint32) (*MaglevInnerMap, error) {
 	return (*MaglevInnerMap)(m), nil
 }
 
+// ResizeMaglevInnerMap creates a new inner map sized for newTableSize and
+// carries over the backend array from old. If the table grows, the remaining
+// slots are filled by repeating the old table so that every slot keeps
+// pointing at a valid backend.
+//
+// The returned map is not yet referenced by any outer map. Callers must swap
+// the outer-map reference atomically via MaglevOuterMap.UpdateService and
+// close the old map afterwards. The carried-over array only approximates the
+// maglev distribution for the new table size and should be recomputed from
+// the backend set, e.g. via maglev.GetLookupTable, as soon as possible.
+func ResizeMaglevInnerMap(logger logrus.FieldLogger, old *MaglevInnerMap, newTableSize uint32) (*MaglevInnerMap, error) {
+	oldTableSize := old.TableSize()
+	if oldTableSize == newTableSize {
+		return old, nil
+	}
+
+	// Backends are stored at inner map key zero.
+	var key MaglevInnerKey
+	val, err := old.Lookup(&key)
+	if err != nil {
+		return nil, fmt.Errorf("reading backends from old inner map: %w", err)
+	}
+
+	inner, err := createMaglevInnerMap(newTableSize)
+	if err != nil {
+		return nil, fmt.Errorf("creating inner map with table size %d: %w", newTableSize, err)
+	}
+
+	backends := make([]loadbalancer.BackendID, newTableSize)
+	copy(backends, val.BackendIDs)
+	for i := oldTableSize; i < newTableSize; i++ {
+		backends[i] = val.BackendIDs[i%oldTableSize]
+	}
+
+	if err := inner.UpdateBackends(backends); err != nil {
+		inner.Close()
+		return nil, fmt.Errorf("updating backends: %w", err)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"oldTableSize": oldTableSize,
+		"newTableSize": newTableSize,
+	}).Debug("Resized maglev inner map")
+
+	return inner, nil
+}
+
 // MaglevInnerMapFromID returns a new object representing the maglev inner map
 // identified by an ID.
 func MaglevInnerMapFromID(id uint32) (*MaglevInnerMap, error) {
@@ -88,6 +137,31 @@ func (m *MaglevInnerMap) Lookup(key *MaglevInnerKey) (*MaglevInnerVal, error) {
 	return value, nil
 }
 
+// VerifyTable compares the map's lookup table against an expected table, e.g.
+// one freshly recomputed through maglev.GetLookupTable, and returns an error
+// describing the first mismatch found. A nil return value means the kernel
+// table matches the expected table.
+func (m *MaglevInnerMap) VerifyTable(expected []int) error {
+	if uint32(len(expected)) != m.TableSize() {
+		return fmt.Errorf("expected table with %d entries, map holds %d", len(expected), m.TableSize())
+	}
+
+	// A service's backend array sits at the first key of the inner map.
+	var key MaglevInnerKey
+	val, err := m.Lookup(&key)
+	if err != nil {
+		return fmt.Errorf("looking up first inner map key (backends): %w", err)
+	}
+
+	for i, backendID := range val.BackendIDs {
+		if loadbalancer.BackendID(expected[i]) != backendID {
+			return fmt.Errorf("lookup table mismatch at index %d: map holds backend %d, expected %d", i, backendID, expected[i])
+		}
+	}
+
+	return nil
+}
+
 // DumpBackends returns the first key of the map as stringified ints for dumping purposes.
 func (m *MaglevInnerMap) DumpBackends() (string, error) {
 	// A service's backend array sits at the first key of the inner map.
@@ -12,6 +12,7 @@ import (
 
 	datapathTypes "github.com/cilium/cilium/pkg/datapath/types"
 	"github.com/cilium/cilium/pkg/loadbalancer"
+	"github.com/cilium/cilium/pkg/maglev"
 	"github.com/cilium/cilium/pkg/option"
 	"github.com/cilium/cilium/pkg/testutils"
 )
@@ -36,14 +37,18 @@ func setupMaglevSuite(tb testing.TB) *MaglevSuite {
 	option.Config.LBMapEntries = DefaultMaxEntries
 	option.Config.NodePortAlg = option.NodePortAlgMaglev
 
-	Init(InitParams{
+	err = Init(InitParams{
 		IPv4: option.Config.EnableIPv4,
 		IPv6: option.Config.EnableIPv6,
 
-		ServiceMapMaxEntries: option.Config.LBMapEntries,
-		RevNatMapMaxEntries:  option.Config.LBMapEntries,
-		MaglevMapMaxEntries:  option.Config.LBMapEntries,
+		ServiceMapMaxEntries:     option.Config.LBMapEntries,
+		BackEndMapMaxEntries:     option.Config.LBMapEntries,
+		RevNatMapMaxEntries:      option.Config.LBMapEntries,
+		AffinityMapMaxEntries:    option.Config.LBMapEntries,
+		SourceRangeMapMaxEntries: option.Config.LBMapEntries,
+		MaglevMapMaxEntries:      option.Config.LBMapEntries,
 	})
+	require.NoError(tb, err)
 
 	tb.Cleanup(func() {
 		option.Config.MaglevTableSize = s.prevMaglevTableSize
@@ -94,3 +99,92 @@ func TestInitMaps(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, false, deleted)
 }
+
+func TestVerifyTable(t *testing.T) {
+	setupMaglevSuite(t)
+
+	option.Config.MaglevTableSize = 251
+	m := uint64(option.Config.MaglevTableSize)
+	err := maglev.Init(maglev.DefaultHashSeed, m)
+	require.NoError(t, err)
+
+	err = InitMaglevMaps(true, false, uint32(m))
+	require.NoError(t, err)
+
+	backends := map[string]*loadbalancer.Backend{
+		"backend-1": {ID: 1, Weight: 1},
+		"backend-2": {ID: 2, Weight: 1},
+	}
+	table := maglev.GetLookupTable(backends, m)
+
+	backendIDs := make([]loadbalancer.BackendID, len(table))
+	for i, id := range table {
+		backendIDs[i] = loadbalancer.BackendID(id)
+	}
+	err = updateMaglevTable(false, 1, backendIDs)
+	require.NoError(t, err)
+
+	inner, err := maglevOuter4Map.GetService(1)
+	require.NoError(t, err)
+	defer inner.Close()
+
+	err = inner.VerifyTable(table)
+	require.NoError(t, err)
+
+	// A table computed for a different backend set must not verify.
+	delete(backends, "backend-2")
+	err = inner.VerifyTable(maglev.GetLookupTable(backends, m))
+	require.Error(t, err)
+}
+
+func TestResizeMaglevInnerMap(t *testing.T) {
+	setupMaglevSuite(t)
+
+	old, err := createMaglevInnerMap(251)
+	require.NoError(t, err)
+	defer old.Close()
+
+	backends := make([]loadbalancer.BackendID, 251)
+	for i := range backends {
+		backends[i] = loadbalancer.BackendID(i%3 + 1)
+	}
+	err = old.UpdateBackends(backends)
+	require.NoError(t, err)
+
+	resized, err := ResizeMaglevInnerMap(log, old, 509)
+	require.NoError(t, err)
+	defer resized.Close()
+	require.Equal(t, uint32(509), resized.TableSize())
+
+	var key MaglevInnerKey
+	val, err := resized.Lookup(&key)
+	require.NoError(t, err)
+	for i, id := range val.BackendIDs {
+		require.Equal(t, backends[i%251], id)
+	}
+
+	// Resizing to the same table size returns the map unchanged.
+	same, err := ResizeMaglevInnerMap(log, old, 251)
+	require.NoError(t, err)
+	require.Equal(t, old, same)
+}
+
+func TestMaglevRecreatedAcknowledge(t *testing.T) {
+	setupMaglevSuite(t)
+
+	option.Config.MaglevTableSize = 251
+	err := InitMaglevMaps(true, false, uint32(option.Config.MaglevTableSize))
+	require.NoError(t, err)
+
+	lbm := New()
+	require.True(t, lbm.IsMaglevLookupTableRecreated(false))
+	lbm.AcknowledgeMaglevRecreated(false)
+	require.False(t, lbm.IsMaglevLookupTableRecreated(false))
+
+	// A second init cycle with a changed M recreates the maps and must
+	// raise the flag again.
+	option.Config.MaglevTableSize = 509
+	err = InitMaglevMaps(true, false, uint32(option.Config.MaglevTableSize))
+	require.NoError(t, err)
+	require.True(t, lbm.IsMaglevLookupTableRecreated(false))
+}
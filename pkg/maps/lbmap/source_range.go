@@ -141,12 +141,16 @@ var (
 
 // initSourceRange creates the BPF maps for storing both IPv4 and IPv6
 // service source ranges.
-func initSourceRange(params InitParams) {
+func initSourceRange(params InitParams) error {
+	if params.SourceRangeMapMaxEntries <= 0 {
+		return fmt.Errorf("invalid maximum number of source range map entries %d", params.SourceRangeMapMaxEntries)
+	}
+
 	SourceRangeMapMaxEntries = params.SourceRangeMapMaxEntries
 
 	if params.IPv4 {
 		SourceRange4Map = bpf.NewMap(
-			SourceRange4MapName,
+			params.pinName(SourceRange4MapName),
 			ebpf.LPMTrie,
 			&SourceRangeKey4{},
 			&SourceRangeValue{},
@@ -158,7 +162,7 @@ func initSourceRange(params InitParams) {
 
 	if params.IPv6 {
 		SourceRange6Map = bpf.NewMap(
-			SourceRange6MapName,
+			params.pinName(SourceRange6MapName),
 			ebpf.LPMTrie,
 			&SourceRangeKey6{},
 			&SourceRangeValue{},
@@ -167,6 +171,8 @@ func initSourceRange(params InitParams) {
 		).WithCache().WithPressureMetric().
 			WithEvents(option.Config.GetEventBufferConfig(SourceRange6MapName))
 	}
+
+	return nil
 }
 
 func srcRangeKey(cidr *cidr.CIDR, revNATID uint16, ipv6 bool) bpf.MapKey {
@@ -129,6 +129,9 @@ type BackendValue interface {
 	// Get backend flags
 	GetFlags() uint8
 
+	// Set backend flags
+	SetFlags(uint8)
+
 	// Get zone
 	GetZone() uint8
 
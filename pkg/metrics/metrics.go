@@ -245,6 +245,9 @@ const (
 
 	// LabelType is the label for type in general (e.g. endpoint, node)
 	LabelType         = "type"
+
+	// LabelTrafficPolicy is the label for the external traffic policy of a service
+	LabelTrafficPolicy = "traffic_policy"
 	LabelPeerEndpoint = "endpoint"
 	LabelPeerNode     = "node"
 
@@ -419,6 +422,26 @@ var (
 	// ServicesEventsCount counts the number of services
 	ServicesEventsCount = NoOpCounterVec
 
+	// ServiceBackendsCount is the number of active backends written on the
+	// last service upsert, labeled by service type and traffic policy.
+	ServiceBackendsCount = NoOpGaugeVec
+
+	// ServiceQuarantinedBackendsTotal counts backends observed in quarantined
+	// state during service upserts.
+	ServiceQuarantinedBackendsTotal = NoOpCounterVec
+
+	// SockRevNatEntries is the occupancy of the socket reverse NAT maps,
+	// labeled by address family.
+	SockRevNatEntries = NoOpGaugeVec
+
+	// LBMapEntries is the occupancy of the load balancing BPF maps, labeled
+	// by map name.
+	LBMapEntries = NoOpGaugeVec
+
+	// LBMapMaxEntries is the configured capacity of the load balancing BPF
+	// maps, labeled by map name.
+	LBMapMaxEntries = NoOpGaugeVec
+
 	// ServiceImplementationDelay the execution duration of the service handler in milliseconds.
 	// The metric reflects the time it took to program the service excluding the event queue latency.
 	ServiceImplementationDelay = NoOpObserverVec
@@ -534,6 +557,10 @@ var (
 	// BPFMapCapacity is the max capacity of bpf maps, labelled by map group classification.
 	BPFMapCapacity = NoOpGaugeVec
 
+	// LBMapUpdateDuration is the duration of load-balancer BPF map update
+	// operations, labelled by map name.
+	LBMapUpdateDuration = NoOpObserverVec
+
 	// TriggerPolicyUpdateTotal is the metric to count total number of
 	// policy update triggers
 	TriggerPolicyUpdateTotal = NoOpCounterVec
@@ -688,6 +715,11 @@ type LegacyMetrics struct {
 	ConntrackDumpResets              metric.Vec[metric.Counter]
 	SignalsHandled                   metric.Vec[metric.Counter]
 	ServicesEventsCount              metric.Vec[metric.Counter]
+	ServiceBackendsCount             metric.Vec[metric.Gauge]
+	ServiceQuarantinedBackendsTotal  metric.Vec[metric.Counter]
+	SockRevNatEntries                metric.Vec[metric.Gauge]
+	LBMapEntries                     metric.Vec[metric.Gauge]
+	LBMapMaxEntries                  metric.Vec[metric.Gauge]
 	ServiceImplementationDelay       metric.Vec[metric.Observer]
 	ErrorsWarnings                   metric.Vec[metric.Counter]
 	ControllerRuns                   metric.Vec[metric.Counter]
@@ -716,6 +748,7 @@ type LegacyMetrics struct {
 	BPFSyscallDuration               metric.Vec[metric.Observer]
 	BPFMapOps                        metric.Vec[metric.Counter]
 	BPFMapCapacity                   metric.Vec[metric.Gauge]
+	LBMapUpdateDuration              metric.Vec[metric.Observer]
 	TriggerPolicyUpdateTotal         metric.Vec[metric.Counter]
 	TriggerPolicyUpdateFolds         metric.Gauge
 	TriggerPolicyUpdateCallDuration  metric.Vec[metric.Observer]
@@ -1006,6 +1039,41 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Number of services events labeled by action type",
 		}, []string{LabelAction}),
 
+		ServiceBackendsCount: metric.NewGaugeVec(metric.GaugeOpts{
+			ConfigName: Namespace + "_service_backends",
+			Namespace:  Namespace,
+			Name:       "service_backends",
+			Help:       "Number of active backends set on the last service upsert labeled by service type and traffic policy",
+		}, []string{LabelType, LabelTrafficPolicy}),
+
+		ServiceQuarantinedBackendsTotal: metric.NewCounterVec(metric.CounterOpts{
+			ConfigName: Namespace + "_service_quarantined_backends_total",
+			Namespace:  Namespace,
+			Name:       "service_quarantined_backends_total",
+			Help:       "Number of quarantined backends observed during service upserts labeled by service type",
+		}, []string{LabelType}),
+
+		SockRevNatEntries: metric.NewGaugeVec(metric.GaugeOpts{
+			ConfigName: Namespace + "_sock_rev_nat_entries",
+			Namespace:  Namespace,
+			Name:       "sock_rev_nat_entries",
+			Help:       "Occupancy of the socket reverse NAT maps labeled by address family",
+		}, []string{LabelDatapathFamily}),
+
+		LBMapEntries: metric.NewGaugeVec(metric.GaugeOpts{
+			ConfigName: Namespace + "_lb_map_entries",
+			Namespace:  Namespace,
+			Name:       "lb_map_entries",
+			Help:       "Occupancy of the load balancing BPF maps labeled by map name",
+		}, []string{LabelMapName}),
+
+		LBMapMaxEntries: metric.NewGaugeVec(metric.GaugeOpts{
+			ConfigName: Namespace + "_lb_map_max_entries",
+			Namespace:  Namespace,
+			Name:       "lb_map_max_entries",
+			Help:       "Configured capacity of the load balancing BPF maps labeled by map name",
+		}, []string{LabelMapName}),
+
 		ServiceImplementationDelay: metric.NewHistogramVec(metric.HistogramOpts{
 			ConfigName: Namespace + "_service_implementation_delay",
 			Namespace:  Namespace,
@@ -1225,6 +1293,15 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Capacity of map, tagged by map group. All maps with a capacity of 65536 are grouped under 'default'",
 		}, []string{LabelMapGroup}),
 
+		LBMapUpdateDuration: metric.NewHistogramVec(metric.HistogramOpts{
+			ConfigName: Namespace + "_" + SubsystemBPF + "_lb_map_update_duration_seconds",
+			Disabled:   true,
+			Namespace:  Namespace,
+			Subsystem:  SubsystemBPF,
+			Name:       "lb_map_update_duration_seconds",
+			Help:       "Duration of load-balancer BPF map update operations, labelled by map name",
+		}, []string{LabelMapName}),
+
 		TriggerPolicyUpdateTotal: metric.NewCounterVec(metric.CounterOpts{
 			ConfigName: Namespace + "_" + SubsystemTriggers + "_policy_update_total",
 			Namespace:  Namespace,
@@ -1409,6 +1486,11 @@ func NewLegacyMetrics() *LegacyMetrics {
 	ConntrackDumpResets = lm.ConntrackDumpResets
 	SignalsHandled = lm.SignalsHandled
 	ServicesEventsCount = lm.ServicesEventsCount
+	ServiceBackendsCount = lm.ServiceBackendsCount
+	ServiceQuarantinedBackendsTotal = lm.ServiceQuarantinedBackendsTotal
+	SockRevNatEntries = lm.SockRevNatEntries
+	LBMapEntries = lm.LBMapEntries
+	LBMapMaxEntries = lm.LBMapMaxEntries
 	ServiceImplementationDelay = lm.ServiceImplementationDelay
 	ErrorsWarnings = lm.ErrorsWarnings
 	ControllerRuns = lm.ControllerRuns
@@ -1437,6 +1519,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 	BPFSyscallDuration = lm.BPFSyscallDuration
 	BPFMapOps = lm.BPFMapOps
 	BPFMapCapacity = lm.BPFMapCapacity
+	LBMapUpdateDuration = lm.LBMapUpdateDuration
 	TriggerPolicyUpdateTotal = lm.TriggerPolicyUpdateTotal
 	TriggerPolicyUpdateFolds = lm.TriggerPolicyUpdateFolds
 	TriggerPolicyUpdateCallDuration = lm.TriggerPolicyUpdateCallDuration
@@ -26,9 +26,10 @@ func TestGaugeWithThreshold(t *testing.T) {
 		threshold,
 	)
 
-	reg := NewRegistry(RegistryParams{
+	reg, err := NewRegistry(RegistryParams{
 		DaemonConfig: &option.DaemonConfig{ConfigPatchMutex: new(lock.RWMutex)},
 	})
+	require.NoError(t, err)
 
 	metrics, err := reg.inner.Gather()
 	require.Nil(t, err)
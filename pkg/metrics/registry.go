@@ -5,8 +5,10 @@ package metrics
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/cilium/hive"
@@ -19,8 +21,10 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/cilium/cilium/api/v1/models"
+	"github.com/cilium/cilium/pkg/lock"
 	metricpkg "github.com/cilium/cilium/pkg/metrics/metric"
 	"github.com/cilium/cilium/pkg/option"
+	"github.com/cilium/cilium/pkg/time"
 )
 
 var defaultRegistryConfig = RegistryConfig{
@@ -32,11 +36,20 @@ type RegistryConfig struct {
 	PrometheusServeAddr string
 	// This is a list of metrics to be enabled or disabled, format is `+`/`-` + `{metric name}`
 	Metrics []string
+	// MetricsRename is a list of `from=to` pairs renaming exposed metrics. Only the
+	// metric name is rewritten, help text and labels are preserved.
+	MetricsRename []string
+	// MetricsCollectBudget is the per-collector duration budget for a single
+	// Collect call. Collectors exceeding it are logged and counted, zero
+	// disables the accounting.
+	MetricsCollectBudget time.Duration
 }
 
 func (rc RegistryConfig) Flags(flags *pflag.FlagSet) {
 	flags.String("prometheus-serve-addr", rc.PrometheusServeAddr, "IP:Port on which to serve prometheus metrics (pass \":Port\" to bind on all interfaces, \"\" is off)")
 	flags.StringSlice("metrics", rc.Metrics, "Metrics that should be enabled or disabled from the default metric list. (+metric_foo to enable metric_foo, -metric_bar to disable metric_bar)")
+	flags.StringSlice("metrics-rename", rc.MetricsRename, "Rename exposed metrics (metric_foo=acme_metric_foo). Only the metric name changes, help text and labels are preserved")
+	flags.Duration("metrics-collect-budget", rc.MetricsCollectBudget, "Per-collector duration budget for metrics collection, collectors exceeding it are logged and counted (0 to disable)")
 }
 
 // RegistryParams are the parameters needed to construct a Registry
@@ -60,11 +73,38 @@ type Registry struct {
 	inner *prometheus.Registry
 
 	params RegistryParams
+
+	// requiredMetricsMu protects requiredMetrics.
+	requiredMetricsMu lock.RWMutex
+	// requiredMetrics holds the fully qualified names of metrics that must be
+	// present in the registry's output for Probe to report healthy.
+	requiredMetrics map[string]struct{}
+
+	// renames maps fully qualified metric names to the names they are exposed
+	// under, as configured via --metrics-rename.
+	renames map[string]string
+	// renamedMu protects renamedCollectors.
+	renamedMu lock.Mutex
+	// renamedCollectors maps registered collectors to the rename wrappers that
+	// were registered in their stead, so that Unregister keeps working on the
+	// original collector.
+	renamedCollectors map[prometheus.Collector]prometheus.Collector
+
+	// slowCollectors counts Collect calls exceeding --metrics-collect-budget,
+	// per collector.
+	slowCollectors *prometheus.CounterVec
 }
 
-func NewRegistry(params RegistryParams) *Registry {
+func NewRegistry(params RegistryParams) (*Registry, error) {
+	renames, err := parseRenames(params.Config.MetricsRename)
+	if err != nil {
+		return nil, fmt.Errorf("parsing --metrics-rename: %w", err)
+	}
+
 	reg := &Registry{
-		params: params,
+		params:         params,
+		renames:        renames,
+		slowCollectors: newSlowCollectorsMetric(),
 	}
 
 	reg.Reinitialize()
@@ -99,16 +139,22 @@ func NewRegistry(params RegistryParams) *Registry {
 		})
 	}
 
-	return reg
+	return reg, nil
 }
 
 // Register registers a collector
 func (r *Registry) Register(c prometheus.Collector) error {
-	return r.inner.Register(c)
+	return r.inner.Register(r.rewire(c))
 }
 
 // Unregister unregisters a collector
 func (r *Registry) Unregister(c prometheus.Collector) bool {
+	r.renamedMu.Lock()
+	if wrapped, ok := r.renamedCollectors[c]; ok {
+		delete(r.renamedCollectors, c)
+		c = wrapped
+	}
+	r.renamedMu.Unlock()
 	return r.inner.Unregister(c)
 }
 
@@ -127,6 +173,9 @@ func (r *Registry) Reinitialize() {
 		)))
 	r.MustRegister(newStatusCollector())
 	r.MustRegister(newbpfCollector())
+	if r.params.Config.MetricsCollectBudget > 0 {
+		r.MustRegister(r.slowCollectors)
+	}
 
 	metrics := make(map[string]metricpkg.WithMetadata)
 	for i, autoMetric := range r.params.AutoMetrics {
@@ -176,11 +225,68 @@ func (r *Registry) Reinitialize() {
 	}
 }
 
+// RequireMetric marks the metric with the given fully qualified name (e.g.
+// "cilium_endpoint_state") as required. Probe reports an error if a required
+// series disappears from the registry's output, e.g. because the subsystem
+// producing it stopped collecting.
+func (r *Registry) RequireMetric(name string) {
+	r.requiredMetricsMu.Lock()
+	defer r.requiredMetricsMu.Unlock()
+	if r.requiredMetrics == nil {
+		r.requiredMetrics = map[string]struct{}{}
+	}
+	r.requiredMetrics[name] = struct{}{}
+}
+
+// Probe gathers the registry and returns an error naming the required metrics
+// (see RequireMetric) that are missing from the output. It ties a liveness
+// signal to actual metric production rather than to the metrics HTTP server
+// merely being up.
+func (r *Registry) Probe() error {
+	r.requiredMetricsMu.RLock()
+	required := make([]string, 0, len(r.requiredMetrics))
+	for name := range r.requiredMetrics {
+		required = append(required, name)
+	}
+	r.requiredMetricsMu.RUnlock()
+
+	if len(required) == 0 {
+		return nil
+	}
+
+	gathered, err := r.inner.Gather()
+	if err != nil {
+		return err
+	}
+
+	present := make(map[string]struct{}, len(gathered))
+	for _, mf := range gathered {
+		if len(mf.Metric) > 0 {
+			present[mf.GetName()] = struct{}{}
+		}
+	}
+
+	var missing []string
+	for _, name := range required {
+		if _, ok := present[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("required metrics not collected: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
 // MustRegister adds the collector to the registry, exposing this metric to
 // prometheus scrapes.
 // It will panic on error.
-func (r *Registry) MustRegister(c ...prometheus.Collector) {
-	r.inner.MustRegister(c...)
+func (r *Registry) MustRegister(cs ...prometheus.Collector) {
+	for _, c := range cs {
+		r.inner.MustRegister(r.rewire(c))
+	}
 }
 
 // RegisterList registers a list of collectors. If registration of one
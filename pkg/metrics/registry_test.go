@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryProbe(t *testing.T) {
+	r := &Registry{inner: prometheus.NewPedanticRegistry()}
+
+	// Without required metrics, Probe is always healthy.
+	require.NoError(t, r.Probe())
+
+	counter := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "test_required_total",
+		Help: "test counter",
+	})
+	require.NoError(t, r.Register(counter))
+	counter.Inc()
+
+	r.RequireMetric("test_required_total")
+	require.NoError(t, r.Probe())
+
+	// A required series that disappears makes the probe fail.
+	r.RequireMetric("test_missing_total")
+	err := r.Probe()
+	require.ErrorContains(t, err, "test_missing_total")
+
+	r.Unregister(counter)
+	err = r.Probe()
+	require.ErrorContains(t, err, "test_required_total")
+}
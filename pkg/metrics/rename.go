@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// parseRenames parses the from=to pairs of the --metrics-rename flag into a
+// lookup map. Duplicate source names and two sources mapped to the same
+// target are rejected, since either would make the exposed names ambiguous.
+func parseRenames(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	renames := make(map[string]string, len(pairs))
+	targets := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		from, to, ok := strings.Cut(pair, "=")
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid rename '%s', expected from=to", pair)
+		}
+		if _, ok := renames[from]; ok {
+			return nil, fmt.Errorf("metric %s is renamed more than once", from)
+		}
+		if prev, ok := targets[to]; ok {
+			return nil, fmt.Errorf("metrics %s and %s are both renamed to %s", prev, from, to)
+		}
+		renames[from] = to
+		targets[to] = from
+	}
+	return renames, nil
+}
+
+// descFQName extracts the fully qualified metric name from the String form of
+// a prometheus.Desc, which is the only public way to get at it.
+var descFQName = regexp.MustCompile(`fqName: "([^"]+)"`)
+
+// collectorMatchesRename reports whether any of the metrics described by c is
+// subject to a configured rename.
+func (r *Registry) collectorMatchesRename(c prometheus.Collector) bool {
+	descs := make(chan *prometheus.Desc)
+	go func() {
+		c.Describe(descs)
+		close(descs)
+	}()
+
+	matched := false
+	for d := range descs {
+		if m := descFQName.FindStringSubmatch(d.String()); m != nil {
+			if _, ok := r.renames[m[1]]; ok {
+				matched = true
+			}
+		}
+	}
+	return matched
+}
+
+// rewire returns the collector to hand to the inner registry: the collector
+// itself when neither a rename nor a collection budget applies to it, or a
+// wrapper exposing the renamed metrics and/or timing Collect otherwise.
+func (r *Registry) rewire(c prometheus.Collector) prometheus.Collector {
+	wrapped := c
+
+	if len(r.renames) > 0 && r.collectorMatchesRename(c) {
+		wrapped = &renamedCollector{inner: wrapped, renames: r.renames}
+	}
+
+	// Time the outermost Collect so the budget covers the full cost of the
+	// collector as seen by a scrape, including any rename overhead. The
+	// budget does not apply to the slow collector counter itself.
+	if budget := r.params.Config.MetricsCollectBudget; budget > 0 && c != prometheus.Collector(r.slowCollectors) {
+		wrapped = &timedCollector{
+			inner:   wrapped,
+			budget:  budget,
+			logger:  r.params.Logger,
+			slowCnt: r.slowCollectors,
+		}
+	}
+
+	if wrapped == c {
+		return c
+	}
+
+	r.renamedMu.Lock()
+	if r.renamedCollectors == nil {
+		r.renamedCollectors = map[prometheus.Collector]prometheus.Collector{}
+	}
+	r.renamedCollectors[c] = wrapped
+	r.renamedMu.Unlock()
+
+	return wrapped
+}
+
+// renamedCollector exposes the metrics of the wrapped collector under the
+// configured names. Only the fully qualified name is rewritten: help text as
+// well as label names and values are carried over unchanged.
+//
+// It works on the gathered protobuf representation and re-emits the samples
+// as constant metrics, which keeps it independent of the concrete collector
+// implementation.
+type renamedCollector struct {
+	inner   prometheus.Collector
+	renames map[string]string
+}
+
+func (c *renamedCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *renamedCollector) Collect(ch chan<- prometheus.Metric) {
+	scratch := prometheus.NewRegistry()
+	if err := scratch.Register(c.inner); err != nil {
+		ch <- prometheus.NewInvalidMetric(prometheus.NewInvalidDesc(err), err)
+		return
+	}
+	families, err := scratch.Gather()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(prometheus.NewInvalidDesc(err), err)
+		return
+	}
+
+	for _, family := range families {
+		name := family.GetName()
+		if to, ok := c.renames[name]; ok {
+			name = to
+		}
+		for _, m := range family.Metric {
+			labelNames := make([]string, 0, len(m.Label))
+			labelValues := make([]string, 0, len(m.Label))
+			for _, lp := range m.Label {
+				labelNames = append(labelNames, lp.GetName())
+				labelValues = append(labelValues, lp.GetValue())
+			}
+			desc := prometheus.NewDesc(name, family.GetHelp(), labelNames, nil)
+			pm, err := constMetric(desc, family.GetType(), m, labelValues)
+			if err != nil {
+				ch <- prometheus.NewInvalidMetric(desc, err)
+				continue
+			}
+			ch <- pm
+		}
+	}
+}
+
+func constMetric(desc *prometheus.Desc, typ dto.MetricType, m *dto.Metric, labelValues []string) (prometheus.Metric, error) {
+	switch typ {
+	case dto.MetricType_COUNTER:
+		return prometheus.NewConstMetric(desc, prometheus.CounterValue, m.GetCounter().GetValue(), labelValues...)
+	case dto.MetricType_GAUGE:
+		return prometheus.NewConstMetric(desc, prometheus.GaugeValue, m.GetGauge().GetValue(), labelValues...)
+	case dto.MetricType_UNTYPED:
+		return prometheus.NewConstMetric(desc, prometheus.UntypedValue, m.GetUntyped().GetValue(), labelValues...)
+	case dto.MetricType_SUMMARY:
+		s := m.GetSummary()
+		quantiles := make(map[float64]float64, len(s.Quantile))
+		for _, q := range s.Quantile {
+			quantiles[q.GetQuantile()] = q.GetValue()
+		}
+		return prometheus.NewConstSummary(desc, s.GetSampleCount(), s.GetSampleSum(), quantiles, labelValues...)
+	case dto.MetricType_HISTOGRAM:
+		h := m.GetHistogram()
+		buckets := make(map[float64]uint64, len(h.Bucket))
+		for _, b := range h.Bucket {
+			buckets[b.GetUpperBound()] = b.GetCumulativeCount()
+		}
+		return prometheus.NewConstHistogram(desc, h.GetSampleCount(), h.GetSampleSum(), buckets, labelValues...)
+	default:
+		return nil, fmt.Errorf("unsupported metric type %s", typ)
+	}
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRenames(t *testing.T) {
+	renames, err := parseRenames(nil)
+	require.NoError(t, err)
+	require.Nil(t, renames)
+
+	renames, err = parseRenames([]string{"cilium_foo_total=acme_foo_total"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"cilium_foo_total": "acme_foo_total"}, renames)
+
+	// Pairs must be of the from=to form.
+	_, err = parseRenames([]string{"cilium_foo_total"})
+	require.ErrorContains(t, err, "expected from=to")
+
+	// Renaming the same metric twice is ambiguous.
+	_, err = parseRenames([]string{"cilium_foo_total=a", "cilium_foo_total=b"})
+	require.ErrorContains(t, err, "renamed more than once")
+
+	// Two metrics mapping to the same name would collide on exposition.
+	_, err = parseRenames([]string{"cilium_foo_total=acme_total", "cilium_bar_total=acme_total"})
+	require.ErrorContains(t, err, "both renamed to acme_total")
+}
+
+func TestRegistryRename(t *testing.T) {
+	r := &Registry{
+		inner: prometheus.NewPedanticRegistry(),
+		renames: map[string]string{
+			"test_renamed_total":            "acme_renamed_total",
+			"test_renamed_duration_seconds": "acme_renamed_duration_seconds",
+		},
+	}
+
+	counters := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "test_renamed_total",
+		Help: "renamed counter",
+	}, []string{"outcome"})
+	counters.WithLabelValues("success").Add(2)
+
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: "test_renamed_duration_seconds",
+		Help: "renamed histogram",
+	})
+	histogram.Observe(0.5)
+
+	untouched := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "test_untouched",
+		Help: "gauge without a rename",
+	})
+
+	require.NoError(t, r.Register(counters))
+	require.NoError(t, r.Register(histogram))
+	require.NoError(t, r.Register(untouched))
+
+	families, err := r.inner.Gather()
+	require.NoError(t, err)
+
+	byName := map[string]*dto.MetricFamily{}
+	for _, family := range families {
+		byName[family.GetName()] = family
+	}
+
+	// The renamed metrics are exposed under their new names only, with help
+	// text, labels and values carried over.
+	require.NotContains(t, byName, "test_renamed_total")
+	require.NotContains(t, byName, "test_renamed_duration_seconds")
+
+	renamed := byName["acme_renamed_total"]
+	require.NotNil(t, renamed)
+	require.Equal(t, "renamed counter", renamed.GetHelp())
+	require.Len(t, renamed.Metric, 1)
+	require.Equal(t, "outcome", renamed.Metric[0].Label[0].GetName())
+	require.Equal(t, "success", renamed.Metric[0].Label[0].GetValue())
+	require.Equal(t, float64(2), renamed.Metric[0].GetCounter().GetValue())
+
+	renamedHist := byName["acme_renamed_duration_seconds"]
+	require.NotNil(t, renamedHist)
+	require.Equal(t, uint64(1), renamedHist.Metric[0].GetHistogram().GetSampleCount())
+
+	// Collectors without a matching rename are registered as-is.
+	require.Contains(t, byName, "test_untouched")
+
+	// Unregister still works on the original collector.
+	require.True(t, r.Unregister(counters))
+	families, err = r.inner.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		require.NotEqual(t, "acme_renamed_total", family.GetName())
+	}
+}
@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// newSlowCollectorsMetric returns the counter tracking collectors whose
+// Collect exceeded the configured budget, labeled by the collector's first
+// fully qualified metric name.
+func newSlowCollectorsMetric() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "metrics_slow_collectors_total",
+		Help:      "Number of metric collections exceeding the --metrics-collect-budget, per collector",
+	}, []string{"collector"})
+}
+
+// collectorName returns the first fully qualified metric name described by c,
+// used to identify the collector in logs and the slow collector metric.
+func collectorName(c prometheus.Collector) string {
+	descs := make(chan *prometheus.Desc)
+	go func() {
+		c.Describe(descs)
+		close(descs)
+	}()
+
+	name := "unknown"
+	for d := range descs {
+		if m := descFQName.FindStringSubmatch(d.String()); m != nil && name == "unknown" {
+			name = m[1]
+		}
+	}
+	return name
+}
+
+// timedCollector wraps a collector and measures the duration of each Collect
+// call. Collections exceeding the budget are logged and counted, pointing
+// operators at the subsystem making /metrics slow under load.
+type timedCollector struct {
+	inner   prometheus.Collector
+	budget  time.Duration
+	logger  logrus.FieldLogger
+	slowCnt *prometheus.CounterVec
+}
+
+func (c *timedCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.inner.Describe(ch)
+}
+
+func (c *timedCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	c.inner.Collect(ch)
+
+	if elapsed := time.Since(start); elapsed > c.budget {
+		name := collectorName(c.inner)
+		c.logger.WithFields(logrus.Fields{
+			"collector": name,
+			"duration":  elapsed,
+			"budget":    c.budget,
+		}).Warn("Metrics collection exceeded budget")
+		c.slowCnt.WithLabelValues(name).Inc()
+	}
+}
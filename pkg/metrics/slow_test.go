@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// slowGauge delays each Collect call to simulate a misbehaving collector.
+type slowGauge struct {
+	prometheus.Gauge
+	delay time.Duration
+}
+
+func (g *slowGauge) Collect(ch chan<- prometheus.Metric) {
+	time.Sleep(g.delay)
+	g.Gauge.Collect(ch)
+}
+
+func TestCollectBudget(t *testing.T) {
+	r := &Registry{
+		inner:          prometheus.NewPedanticRegistry(),
+		slowCollectors: newSlowCollectorsMetric(),
+	}
+	r.params.Logger = logrus.New()
+	r.params.Config.MetricsCollectBudget = time.Millisecond
+
+	slow := &slowGauge{
+		Gauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "test_slow_gauge",
+			Help: "gauge with a slow collector",
+		}),
+		delay: 10 * time.Millisecond,
+	}
+	fast := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "test_fast_gauge",
+		Help: "gauge answering within the budget",
+	})
+
+	require.NoError(t, r.Register(r.slowCollectors))
+	require.NoError(t, r.Register(slow))
+	require.NoError(t, r.Register(fast))
+
+	_, err := r.inner.Gather()
+	require.NoError(t, err)
+
+	// Only the slow collector is counted, under its first metric name.
+	families, err := r.inner.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "cilium_metrics_slow_collectors_total" {
+			continue
+		}
+		require.Len(t, family.Metric, 1)
+		require.Equal(t, "collector", family.Metric[0].Label[0].GetName())
+		require.Equal(t, "test_slow_gauge", family.Metric[0].Label[0].GetValue())
+		require.NotZero(t, family.Metric[0].GetCounter().GetValue())
+		return
+	}
+	t.Fatal("slow collector counter not found in output")
+}
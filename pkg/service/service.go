@@ -693,6 +693,14 @@ func (s *Service) upsertService(params *lb.SVC) (bool, lb.ID, error) {
 		params.SessionAffinityTimeoutSec = 0
 	}
 
+	// Reject invalid session affinity timeouts here instead of failing deep
+	// in the BPF map write.
+	if params.SessionAffinity {
+		if err := lbmap.ValidateSessionAffinityTimeout(params.SessionAffinityTimeoutSec); err != nil {
+			return false, lb.ID(0), err
+		}
+	}
+
 	// Implement a "lazy load" function for the scoped logger, so the expensive
 	// call to 'WithFields' is only done if needed.
 	debugLogsEnabled := logging.CanLogAt(log.Logger, logrus.DebugLevel)
@@ -1058,7 +1066,7 @@ func (s *Service) UpdateBackendsState(backends []*lb.Backend) error {
 						LoopbackHostport:          info.LoopbackHostport,
 					}
 				}
-				p.PreferredBackends, p.ActiveBackends, p.NonActiveBackends = segregateBackends(info.backends)
+				p.PreferredBackends, p.ActiveBackends, p.NonActiveBackends, p.QuarantinedBackends = segregateBackends(info.backends)
 				updateSvcs[id] = p
 				log.WithFields(logrus.Fields{
 					logfields.ServiceID:        p.ID,
@@ -1186,6 +1194,12 @@ func (s *Service) RestoreServices() error {
 	if option.Config.EnableSVCSourceRangeCheck {
 		errs = errors.Join(errs, s.restoreAndDeleteOrphanSourceRanges())
 	}
+
+	// All maglev lookup tables have been re-pushed at this point, so reset
+	// the recreated flags to only reflect subsequent reinitializations.
+	s.lbmap.AcknowledgeMaglevRecreated(false)
+	s.lbmap.AcknowledgeMaglevRecreated(true)
+
 	return errs
 }
 
@@ -1249,7 +1263,7 @@ func (s *Service) restoreAndDeleteOrphanSourceRanges() error {
 			svc, found := s.svcByID[lb.ID(svcID)]
 			if !found {
 				// Delete ranges
-				if err := s.lbmap.UpdateSourceRanges(svcID, srcRanges, nil, ipv6); err != nil {
+				if _, _, err := s.lbmap.UpdateSourceRanges(svcID, srcRanges, nil, ipv6); err != nil {
 					return err
 				}
 			} else {
@@ -1501,7 +1515,7 @@ func (s *Service) upsertServiceIntoLBMaps(svc *svcInfo, isExtLocal, isIntLocal b
 
 	// Update LB source range check cidrs
 	if checkLBSrcRange = svc.checkLBSourceRange() || len(prevLoadBalancerSourceRanges) != 0; checkLBSrcRange {
-		if err := s.lbmap.UpdateSourceRanges(uint16(svc.frontend.ID),
+		if _, _, err := s.lbmap.UpdateSourceRanges(uint16(svc.frontend.ID),
 			prevLoadBalancerSourceRanges, svc.loadBalancerSourceRanges,
 			v6FE); err != nil {
 			return err
@@ -1524,7 +1538,7 @@ func (s *Service) upsertServiceIntoLBMaps(svc *svcInfo, isExtLocal, isIntLocal b
 	}
 
 	// Upsert service entries into BPF maps
-	preferredBackends, activeBackends, nonActiveBackends := segregateBackends(svc.backends)
+	preferredBackends, activeBackends, nonActiveBackends, quarantinedBackends := segregateBackends(svc.backends)
 
 	natPolicy := lb.SVCNatPolicyNone
 	natPolicySet := false
@@ -1562,6 +1576,7 @@ func (s *Service) upsertServiceIntoLBMaps(svc *svcInfo, isExtLocal, isIntLocal b
 		PreferredBackends:         preferredBackends,
 		ActiveBackends:            activeBackends,
 		NonActiveBackends:         nonActiveBackends,
+		QuarantinedBackends:       quarantinedBackends,
 		PrevBackendsCount:         prevBackendCount,
 		IPv6:                      v6FE,
 		NatPolicy:                 natPolicy,
@@ -1722,6 +1737,9 @@ func (s *Service) deleteOrphanBackends() error {
 func (s *Service) restoreServicesLocked(svcBackendsById map[lb.BackendID]struct{}) error {
 	failed, restored := 0, 0
 
+	// One snapshot for both families, consulted per restored service below.
+	v4MaglevRecreated, v6MaglevRecreated := s.lbmap.MaglevRecreatedStatus()
+
 	svcs, errors := s.lbmap.DumpServiceMaps()
 	for _, err := range errors {
 		log.WithError(err).Warning("Error occurred while dumping service maps")
@@ -1783,7 +1801,10 @@ func (s *Service) restoreServicesLocked(svcBackendsById map[lb.BackendID]struct{
 		// Recalculate Maglev lookup tables if the maps were removed due to
 		// the changed M param.
 		ipv6 := newSVC.frontend.IsIPv6() || (svc.NatPolicy == lb.SVCNatPolicyNat46)
-		recreated := s.lbmap.IsMaglevLookupTableRecreated(ipv6)
+		recreated := v4MaglevRecreated
+		if ipv6 {
+			recreated = v6MaglevRecreated
+		}
 		if option.Config.DatapathMode == datapathOpt.DatapathModeLBOnly &&
 			newSVC.useMaglev() && recreated {
 
@@ -1842,7 +1863,7 @@ func (s *Service) deleteServiceLocked(svc *svcInfo) error {
 
 	if option.Config.EnableSVCSourceRangeCheck &&
 		svc.svcType == lb.SVCTypeLoadBalancer {
-		if err := s.lbmap.UpdateSourceRanges(uint16(svc.frontend.ID),
+		if _, _, err := s.lbmap.UpdateSourceRanges(uint16(svc.frontend.ID),
 			svc.loadBalancerSourceRanges, nil, ipv6); err != nil {
 			return err
 		}
@@ -2037,7 +2058,7 @@ func isWildcardAddr(frontend lb.L3n4AddrID) bool {
 // added to the lbmaps. If EnableK8sTerminatingEndpoint and there are no active backends,
 // segregateBackends will return all terminating backends as active.
 func segregateBackends(backends []*lb.Backend) (preferredBackends map[string]*lb.Backend,
-	activeBackends map[string]*lb.Backend, nonActiveBackends []lb.BackendID,
+	activeBackends map[string]*lb.Backend, nonActiveBackends []lb.BackendID, quarantinedBackends int,
 ) {
 	preferredBackends = make(map[string]*lb.Backend)
 	activeBackends = make(map[string]*lb.Backend, len(backends))
@@ -2055,6 +2076,9 @@ func segregateBackends(backends []*lb.Backend) (preferredBackends map[string]*lb
 				preferredBackends[b.String()] = b
 			}
 		} else {
+			if b.State == lb.BackendStateQuarantined {
+				quarantinedBackends++
+			}
 			nonActiveBackends = append(nonActiveBackends, b.ID)
 		}
 	}
@@ -2073,7 +2097,7 @@ func segregateBackends(backends []*lb.Backend) (preferredBackends map[string]*lb
 			}
 		}
 	}
-	return preferredBackends, activeBackends, nonActiveBackends
+	return preferredBackends, activeBackends, nonActiveBackends, quarantinedBackends
 }
 
 // SyncNodePortFrontends updates all NodePort services with a new set of frontend
@@ -157,33 +157,37 @@ func attachCgroup(spec *ebpf.Collection, name, cgroupRoot, pinPath string) error
 // detachCgroup detaches a program with the given name from cgroupRoot. Attempts
 // to open a pinned link with the given name from directory pinPath first,
 // falling back to PROG_DETACH if no pin is present.
-func detachCgroup(name, cgroupRoot, pinPath string) error {
+//
+// Returns true if a program was actually detached, false if the program was
+// already absent.
+func detachCgroup(name, cgroupRoot, pinPath string) (bool, error) {
 	pin := filepath.Join(pinPath, name)
 	err := bpf.UnpinLink(pin)
 	if err == nil {
-		return nil
+		return true, nil
 	}
 
 	if !errors.Is(err, os.ErrNotExist) {
 		// The pinned link exists, something went wrong unpinning it.
-		return fmt.Errorf("unpinning cgroup program using bpf_link: %w", err)
+		return false, fmt.Errorf("unpinning cgroup program using bpf_link: %w", err)
 	}
 
 	// No bpf_link pin found, detach all prog_attach progs.
 	log.Debugf("No pinned link '%s', querying cgroup", pin)
-	err = detachAll(attachTypes[name], cgroupRoot)
+	detached, err := detachAll(attachTypes[name], cgroupRoot)
 	// Treat detaching unsupported attach types as successful.
 	if errors.Is(err, link.ErrNotSupported) {
-		return nil
+		return false, nil
 	}
-	return err
+	return detached, err
 }
 
 // detachAll detaches all programs attached to cgroupRoot with the corresponding attach type.
-func detachAll(attach ebpf.AttachType, cgroupRoot string) error {
+// Returns true if at least one program was detached.
+func detachAll(attach ebpf.AttachType, cgroupRoot string) (bool, error) {
 	cg, err := os.Open(cgroupRoot)
 	if err != nil {
-		return fmt.Errorf("open cgroup %s: %w", cg.Name(), err)
+		return false, fmt.Errorf("open cgroup %s: %w", cg.Name(), err)
 	}
 	defer cg.Close()
 
@@ -199,11 +203,11 @@ func detachAll(attach ebpf.AttachType, cgroupRoot string) error {
 		err = fmt.Errorf("%w: %w", err, link.ErrNotSupported)
 	}
 	if err != nil {
-		return fmt.Errorf("query cgroup %s for type %s: %w", cgroupRoot, attach, err)
+		return false, fmt.Errorf("query cgroup %s for type %s: %w", cgroupRoot, attach, err)
 	}
 	if ids == nil || len(ids.Programs) == 0 {
 		log.Debugf("No programs in cgroup %s with attach type %s", cgroupRoot, attach)
-		return nil
+		return false, nil
 	}
 
 	// cilium owns the cgroup and assumes only one program is attached.
@@ -211,7 +215,7 @@ func detachAll(attach ebpf.AttachType, cgroupRoot string) error {
 	for _, id := range ids.Programs {
 		prog, err := ebpf.NewProgramFromID(id.ID)
 		if err != nil {
-			return fmt.Errorf("could not open program id %d: %w", id, err)
+			return false, fmt.Errorf("could not open program id %d: %w", id, err)
 		}
 		defer prog.Close()
 
@@ -220,11 +224,11 @@ func detachAll(attach ebpf.AttachType, cgroupRoot string) error {
 			Program: prog,
 			Attach:  attach,
 		}); err != nil {
-			return fmt.Errorf("detach programs from cgroup %s attach type %s: %w", cgroupRoot, attach, err)
+			return false, fmt.Errorf("detach programs from cgroup %s attach type %s: %w", cgroupRoot, attach, err)
 		}
 
 		log.Debugf("Detached program id %d", id)
 	}
 
-	return nil
+	return true, nil
 }
@@ -45,7 +45,7 @@ func TestAttachCgroup(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := detachCgroup("test", cgroupPath, linkPath); err != nil {
+	if _, err := detachCgroup("test", cgroupPath, linkPath); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -80,7 +80,7 @@ func TestAttachCgroupWithPreviousAttach(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := detachCgroup("test", cgroupPath, linkPath); err != nil {
+	if _, err := detachCgroup("test", cgroupPath, linkPath); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -122,7 +122,7 @@ func TestAttachCgroupWithExistingLink(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := detachCgroup("test", cgroupPath, linkPath); err != nil {
+	if _, err := detachCgroup("test", cgroupPath, linkPath); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -148,7 +148,7 @@ func TestDetachCGroupWithPreviousAttach(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := detachCgroup("test", cgroupPath, linkPath); err != nil {
+	if _, err := detachCgroup("test", cgroupPath, linkPath); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -181,7 +181,7 @@ func TestDetachCGroupWithExistingLink(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := detachCgroup("test", cgroupPath, linkPath); err != nil {
+	if _, err := detachCgroup("test", cgroupPath, linkPath); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -8,14 +8,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/cilium/ebpf"
+	"github.com/sirupsen/logrus"
 
 	"github.com/cilium/cilium/pkg/bpf"
 	"github.com/cilium/cilium/pkg/cgroups"
+	"github.com/cilium/cilium/pkg/datapath/linux/probes"
 	"github.com/cilium/cilium/pkg/datapath/linux/sysctl"
 	"github.com/cilium/cilium/pkg/logging"
 	"github.com/cilium/cilium/pkg/logging/logfields"
+	"github.com/cilium/cilium/pkg/mountinfo"
 	"github.com/cilium/cilium/pkg/option"
 )
 
@@ -45,18 +49,63 @@ var (
 		RecvMsg6, GetPeerName6, PostBind6, PreBind6}
 )
 
+// haveGetPeerNameHooks returns nil if the kernel supports attaching cgroup
+// programs to the getpeername(2) hooks. Both CGROUP_INET4_GETPEERNAME and
+// CGROUP_INET6_GETPEERNAME landed together in Linux 5.8, but probe both to be
+// safe. Results are cached by the probes package.
+func haveGetPeerNameHooks() error {
+	if err := probes.HaveAttachType(ebpf.CGroupSockAddr, ebpf.AttachCgroupInet4GetPeername); err != nil {
+		return err
+	}
+	return probes.HaveAttachType(ebpf.CGroupSockAddr, ebpf.AttachCgroupInet6GetPeername)
+}
+
 // TODO: Clean up bpffs root logic and make this a var.
 func cgroupLinkPath() string {
 	return filepath.Join(bpf.CiliumPath(), Subsystem, "links/cgroup")
 }
 
+// resolveCgroupRoot returns the cgroup root socketlb programs are to be
+// attached to. An empty or absent custom root falls back to
+// cgroups.GetCgroupRoot(). A custom root, e.g. in nested container
+// environments where the default mount is not the desired attach point, is
+// validated to exist and to be a cgroup2 mount.
+func resolveCgroupRoot(custom ...string) (string, error) {
+	if len(custom) == 0 || custom[0] == "" {
+		return cgroups.GetCgroupRoot(), nil
+	}
+
+	root := custom[0]
+	if _, err := os.Stat(root); err != nil {
+		return "", fmt.Errorf("statting custom cgroup root %s: %w", root, err)
+	}
+
+	mounted, cgroupInstance, err := mountinfo.IsMountFS(mountinfo.FilesystemTypeCgroup2, root)
+	if err != nil {
+		return "", fmt.Errorf("checking custom cgroup root %s: %w", root, err)
+	}
+	if !mounted || !cgroupInstance {
+		return "", fmt.Errorf("custom cgroup root %s is not a cgroup2 mount", root)
+	}
+
+	return root, nil
+}
+
 // Enable attaches necessary bpf programs for socketlb based on ciliums config.
 //
 // On restart, Enable can also detach unnecessary programs if specific configuration
 // options have changed.
 // It expects bpf_sock.c to be compiled previously, so that bpf_sock.o is present
 // in the Runtime dir.
-func Enable(sysctl sysctl.Sysctl) error {
+//
+// An optional cgroupRoot can be given to attach to a specific cgroup v2 mount
+// instead of the default cgroups.GetCgroupRoot().
+func Enable(sysctl sysctl.Sysctl, cgroupRoot ...string) error {
+	cgrpRoot, err := resolveCgroupRoot(cgroupRoot...)
+	if err != nil {
+		return fmt.Errorf("resolve cgroup root: %w", err)
+	}
+
 	if err := os.MkdirAll(cgroupLinkPath(), 0777); err != nil {
 		return fmt.Errorf("create bpffs link directory: %w", err)
 	}
@@ -88,12 +137,23 @@ func Enable(sysctl sysctl.Sysctl) error {
 		enabled[p] = false
 	}
 
+	enablePeer := option.Config.EnableSocketLBPeer
+	if enablePeer {
+		if err := haveGetPeerNameHooks(); err != nil {
+			if !errors.Is(err, ebpf.ErrNotSupported) {
+				return fmt.Errorf("probing getpeername hook support: %w", err)
+			}
+			log.WithError(err).Warn("Kernel does not support getpeername cgroup hooks, skipping socket-LB peer programs")
+			enablePeer = false
+		}
+	}
+
 	if option.Config.EnableIPv4 {
 		enabled[Connect4] = true
 		enabled[SendMsg4] = true
 		enabled[RecvMsg4] = true
 
-		if option.Config.EnableSocketLBPeer {
+		if enablePeer {
 			enabled[GetPeerName4] = true
 		}
 
@@ -115,7 +175,7 @@ func Enable(sysctl sysctl.Sysctl) error {
 		enabled[SendMsg6] = true
 		enabled[RecvMsg6] = true
 
-		if option.Config.EnableSocketLBPeer {
+		if enablePeer {
 			enabled[GetPeerName6] = true
 		}
 
@@ -130,12 +190,12 @@ func Enable(sysctl sysctl.Sysctl) error {
 
 	for p, s := range enabled {
 		if s {
-			if err := attachCgroup(coll, p, cgroups.GetCgroupRoot(), cgroupLinkPath()); err != nil {
+			if err := attachCgroup(coll, p, cgrpRoot, cgroupLinkPath()); err != nil {
 				return fmt.Errorf("cgroup attach: %w", err)
 			}
 			continue
 		}
-		if err := detachCgroup(p, cgroups.GetCgroupRoot(), cgroupLinkPath()); err != nil {
+		if _, err := detachCgroup(p, cgrpRoot, cgroupLinkPath()); err != nil {
 			return fmt.Errorf("cgroup detach: %w", err)
 		}
 	}
@@ -147,13 +207,73 @@ func Enable(sysctl sysctl.Sysctl) error {
 	return nil
 }
 
-// Disable detaches all bpf programs for socketlb.
-func Disable() error {
+// AttachedPrograms returns the names of the socketlb cgroup programs that are
+// currently attached via a pinned bpf_link, by listing the pins under
+// cgroupLinkPath(). Programs attached through PROG_ATTACH on pre-5.7 kernels
+// have no pin and are not reported. A missing pin directory means no programs
+// are attached and yields an empty list.
+func AttachedPrograms(logger logrus.FieldLogger) ([]string, error) {
+	return attachedPrograms(logger, cgroupLinkPath())
+}
+
+func attachedPrograms(logger logrus.FieldLogger, pinPath string) ([]string, error) {
+	entries, err := os.ReadDir(pinPath)
+	if errors.Is(err, os.ErrNotExist) {
+		logger.Debugf("Link directory %s does not exist, no socketlb programs attached", pinPath)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading link directory %s: %w", pinPath, err)
+	}
+
+	known := make(map[string]struct{}, len(cgroupProgs))
 	for _, p := range cgroupProgs {
-		if err := detachCgroup(p, cgroups.GetCgroupRoot(), cgroupLinkPath()); err != nil {
-			return fmt.Errorf("detach cgroup: %w", err)
+		known[p] = struct{}{}
+	}
+
+	var attached []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if _, ok := known[e.Name()]; !ok {
+			logger.Debugf("Ignoring unknown pin %s in %s", e.Name(), pinPath)
+			continue
 		}
+		attached = append(attached, e.Name())
 	}
+	sort.Strings(attached)
 
-	return nil
+	return attached, nil
+}
+
+// Disable detaches all bpf programs for socketlb. Programs that were never
+// attached, e.g. because socketlb was only partially enabled, are skipped and
+// do not abort the teardown of the remaining programs. Per-program errors are
+// aggregated and returned after all programs have been processed, making
+// Disable safe to call repeatedly.
+//
+// An optional cgroupRoot can be given to detach from a specific cgroup v2
+// mount instead of the default cgroups.GetCgroupRoot().
+func Disable(cgroupRoot ...string) error {
+	cgrpRoot, err := resolveCgroupRoot(cgroupRoot...)
+	if err != nil {
+		return fmt.Errorf("resolve cgroup root: %w", err)
+	}
+
+	var errs error
+	for _, p := range cgroupProgs {
+		detached, err := detachCgroup(p, cgrpRoot, cgroupLinkPath())
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("detach cgroup program %s: %w", p, err))
+			continue
+		}
+		if detached {
+			log.Debugf("Detached program %s", p)
+		} else {
+			log.Debugf("Program %s was not attached, nothing to detach", p)
+		}
+	}
+
+	return errs
 }
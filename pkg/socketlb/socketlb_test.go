@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package socketlb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachedPrograms(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(os.Stderr)
+
+	// A missing pin directory means nothing is attached.
+	attached, err := attachedPrograms(logger, filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	require.Empty(t, attached)
+
+	// Only pins matching known socketlb program names are reported, sorted.
+	dir := t.TempDir()
+	for _, name := range []string{GetPeerName6, Connect4, "some_other_pin"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "subdir"), 0o755))
+
+	attached, err = attachedPrograms(logger, dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{Connect4, GetPeerName6}, attached)
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package socketlb
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+
+	"github.com/cilium/cilium/pkg/datapath/linux/sysctl"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// ErrStatsUnavailable is returned by Stats when the kernel is not collecting
+// bpf program statistics, either because kernel.bpf_stats_enabled is off or
+// because the kernel predates the run_cnt/run_time_ns program info fields.
+var ErrStatsUnavailable = errors.New("bpf program statistics are unavailable")
+
+// ProgramStats are the kernel-reported run statistics of a single attached
+// socketlb program.
+type ProgramStats struct {
+	// Name is the program name, one of the constants of this package.
+	Name string
+	// RunCount is the number of times the program has been executed.
+	RunCount uint64
+	// Runtime is the accumulated time spent executing the program.
+	Runtime time.Duration
+}
+
+// Stats returns the run statistics of all socketlb programs attached via a
+// pinned bpf_link, in the order reported by AttachedPrograms. It returns
+// ErrStatsUnavailable if the kernel is not collecting bpf program statistics,
+// so callers can report the condition instead of treating it as a failure.
+func Stats(sysctl sysctl.Sysctl) ([]ProgramStats, error) {
+	return stats(sysctl, cgroupLinkPath())
+}
+
+func stats(sysctl sysctl.Sysctl, pinPath string) ([]ProgramStats, error) {
+	enabled, err := sysctl.ReadInt("kernel.bpf_stats_enabled")
+	if err != nil || enabled == 0 {
+		return nil, ErrStatsUnavailable
+	}
+
+	attached, err := attachedPrograms(log, pinPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []ProgramStats
+	for _, name := range attached {
+		stats, err := programStats(filepath.Join(pinPath, name))
+		if err != nil {
+			return nil, fmt.Errorf("reading stats of program %s: %w", name, err)
+		}
+		stats.Name = name
+		out = append(out, stats)
+	}
+
+	return out, nil
+}
+
+// programStats resolves the program behind the pinned link at pin and reads
+// its run statistics from the kernel.
+func programStats(pin string) (ProgramStats, error) {
+	l, err := link.LoadPinnedLink(pin, nil)
+	if err != nil {
+		return ProgramStats{}, fmt.Errorf("loading pinned link %s: %w", pin, err)
+	}
+	defer l.Close()
+
+	info, err := l.Info()
+	if err != nil {
+		return ProgramStats{}, fmt.Errorf("getting link info: %w", err)
+	}
+
+	prog, err := ebpf.NewProgramFromID(info.Program)
+	if err != nil {
+		return ProgramStats{}, fmt.Errorf("opening program %d: %w", info.Program, err)
+	}
+	defer prog.Close()
+
+	pi, err := prog.Info()
+	if err != nil {
+		return ProgramStats{}, fmt.Errorf("getting program info: %w", err)
+	}
+
+	runCount, haveCount := pi.RunCount()
+	runtime, haveTime := pi.Runtime()
+	if !haveCount || !haveTime {
+		return ProgramStats{}, ErrStatsUnavailable
+	}
+
+	return ProgramStats{RunCount: runCount, Runtime: runtime}, nil
+}
@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package socketlb
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cilium/cilium/pkg/datapath/linux/sysctl"
+)
+
+func TestStatsUnavailable(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sc := sysctl.NewDirectSysctl(fs, "/proc")
+
+	// A kernel without the bpf_stats_enabled sysctl cannot collect stats.
+	_, err := stats(sc, t.TempDir())
+	require.ErrorIs(t, err, ErrStatsUnavailable)
+
+	// Stats collection present but switched off.
+	require.NoError(t, afero.WriteFile(fs, "/proc/sys/kernel/bpf_stats_enabled", []byte("0\n"), 0o644))
+	_, err = stats(sc, t.TempDir())
+	require.ErrorIs(t, err, ErrStatsUnavailable)
+
+	// Collection enabled and no programs attached yields an empty result.
+	require.NoError(t, afero.WriteFile(fs, "/proc/sys/kernel/bpf_stats_enabled", []byte("1\n"), 0o644))
+	out, err := stats(sc, t.TempDir())
+	require.NoError(t, err)
+	require.Empty(t, out)
+}
@@ -95,10 +95,17 @@ func (m *LBMockMap) UpsertMaglevLookupTable(svcID uint16, backends map[string]*l
 	return m.upsertMaglevLookupTable(svcID, backends, ipv6)
 }
 
+func (*LBMockMap) AcknowledgeMaglevRecreated(ipv6 bool) {
+}
+
 func (*LBMockMap) IsMaglevLookupTableRecreated(ipv6 bool) bool {
 	return true
 }
 
+func (*LBMockMap) MaglevRecreatedStatus() (v4, v6 bool) {
+	return true, true
+}
+
 func (m *LBMockMap) DeleteService(addr lb.L3n4AddrID, backendCount int, maglev bool, natPolicy lb.SVCNatPolicy) error {
 	m.Lock()
 	defer m.Unlock()
@@ -219,7 +226,7 @@ func (m *LBMockMap) DumpAffinityMatches() (datapathTypes.BackendIDByServiceIDSet
 }
 
 func (m *LBMockMap) UpdateSourceRanges(revNATID uint16, prevRanges []*cidr.CIDR,
-	ranges []*cidr.CIDR, ipv6 bool) error {
+	ranges []*cidr.CIDR, ipv6 bool) (added, removed []*cidr.CIDR, err error) {
 	m.Lock()
 	defer m.Unlock()
 
@@ -227,18 +234,32 @@ func (m *LBMockMap) UpdateSourceRanges(revNATID uint16, prevRanges []*cidr.CIDR,
 		m.SourceRanges[revNATID] = []*cidr.CIDR{}
 	}
 	if len(prevRanges) != len(m.SourceRanges[revNATID]) {
-		return fmt.Errorf("Inconsistent view of source ranges")
+		return nil, nil, fmt.Errorf("Inconsistent view of source ranges")
+	}
+	prev := map[string]*cidr.CIDR{}
+	for _, cidr := range prevRanges {
+		prev[cidr.String()] = cidr
 	}
 	srcRanges := []*cidr.CIDR{}
+	next := map[string]struct{}{}
 	for _, cidr := range ranges {
 		if ip.IsIPv6(cidr.IP) == !ipv6 {
 			continue
 		}
 		srcRanges = append(srcRanges, cidr)
+		next[cidr.String()] = struct{}{}
+		if _, found := prev[cidr.String()]; !found {
+			added = append(added, cidr)
+		}
+	}
+	for _, cidr := range prevRanges {
+		if _, found := next[cidr.String()]; !found {
+			removed = append(removed, cidr)
+		}
 	}
 	m.SourceRanges[revNATID] = srcRanges
 
-	return nil
+	return added, removed, nil
 }
 
 func (m *LBMockMap) DumpSourceRanges(ipv6 bool) (datapathTypes.SourceRangeSetByServiceID, error) {